@@ -61,7 +61,7 @@ func (ai *AILink) totalInputBytes(n int) int {
 // the board state as one-hot encoding
 func (ai *AILink) Inputs() []byte {
 
-	matrix := ai.engine.GameBoard.TileMatrix
+	matrix, _ := ai.engine.GameBoard.Matrix()
 	l := matrix.Len()
 
 	bytesPerTile := ai.totalInputBytes(1)
@@ -86,7 +86,7 @@ func printBytes(bs []byte) {
 }
 
 func (ai *AILink) saveImg(data []byte) {
-	matrix := ai.engine.GameBoard.TileMatrix
+	matrix, _ := ai.engine.GameBoard.Matrix()
 	l := matrix.Len()
 
 	img := image.NewGray(image.Rect(0, 0, ai.totalInputs(1), l))
@@ -7,8 +7,18 @@ import (
 )
 
 func BenchmarkAILink_Inputs(b *testing.B) {
-	gameData := data.LoadGameData("../data/bitmaps", "../data/standard_deck.yml")
-	engineInstance := engine.NewEngine(gameData, 16, 4)
+	gameData, err := data.LoadGameData("../data/bitmaps", "../data/standard_deck.yml")
+
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	engineInstance, err := engine.NewEngine(gameData, 16, 4, 1)
+
+	if err != nil {
+		b.Fatal(err)
+	}
+
 	ai := NewAILink(engineInstance)
 
 	// steps to complete game
@@ -5,6 +5,7 @@ import (
 	"beeb/carcassonne/imageHelpers"
 	"beeb/carcassonne/matrix"
 	"beeb/carcassonne/util"
+	"errors"
 	"fmt"
 	"image"
 	"image/color"
@@ -14,34 +15,73 @@ import (
 	"github.com/google/uuid"
 )
 
+var (
+	ErrInvalidBitmap = errors.New("invalid tile bitmap")
+	ErrInvalidDeck   = errors.New("invalid deck file")
+	ErrMissingBitmap = errors.New("deck tile has no bitmap")
+)
+
+// LoadError
+// a file or directory which couldn't be loaded as game data, Err is why
+type LoadError struct {
+	Path string
+	Err  error
+}
+
+func (err *LoadError) Error() string {
+	return fmt.Sprint("loading ", err.Path, ": ", err.Err)
+}
+
+func (err *LoadError) Unwrap() error {
+	return err.Err
+}
+
 type DeckInfo struct {
 	Deck map[string]int
 }
 
 type GameData struct {
+	BitmapDirectory string
+	DeckFilePath    string
+
 	TileNames []string
 	Bitmaps   map[string]image.Image
 	DeckInfo  DeckInfo
 
+	//the rules new engines are made with, the standard rules unless replaced
+	Rules *RuleSet
+
 	//mapped by name, then by orientation (0 = 0, 1 = 90, 2 = 180, 3 = 270 degrees)
 	ReferenceTileGroups map[string]*tile.ReferenceTileGroup
 }
 
-func LoadGameData(bitmapDirectory string, deckFilePath string) *GameData {
+// LoadGameData
+// loads the tile bitmaps and the deck made from them. the errors returned are a *LoadError,
+// saying which file couldn't be loaded
+func LoadGameData(bitmapDirectory string, deckFilePath string) (*GameData, error) {
 	gameData := &GameData{}
+	gameData.BitmapDirectory = bitmapDirectory
+	gameData.DeckFilePath = deckFilePath
+
+	if err := gameData.loadBitmaps(bitmapDirectory); err != nil {
+		return nil, err
+	}
+
+	if err := gameData.loadDeckInfo(deckFilePath); err != nil {
+		return nil, err
+	}
 
-	gameData.loadBitmaps(bitmapDirectory)
-	gameData.loadDeckInfo(deckFilePath)
 	gameData.compileReferenceTiles()
+	gameData.Rules = DefaultRuleSet()
 
-	return gameData
+	return gameData, nil
 }
 
-func (gd *GameData) loadDeckInfo(deckFilePath string) {
+func (gd *GameData) loadDeckInfo(deckFilePath string) error {
 	di, err := LoadDeckInfo(deckFilePath)
 
 	if err != nil {
-		panic(err)
+		return &LoadError{Path: deckFilePath, Err: err}
 	}
 
 	for deckFileTileName, _ := range di.Deck {
@@ -54,16 +94,24 @@ func (gd *GameData) loadDeckInfo(deckFilePath string) {
 		}
 
 		if !bitmapExists {
-			panic(fmt.Sprint("Missing bmp file for deck tile: ", deckFileTileName))
+			return &LoadError{
+				Path: deckFilePath,
+				Err:  fmt.Errorf("%w: %s", ErrMissingBitmap, deckFileTileName),
+			}
 		}
 	}
 
 	gd.DeckInfo = di
+
+	return nil
 }
 
-func (gd *GameData) loadBitmaps(bitmapDirectory string) {
+func (gd *GameData) loadBitmaps(bitmapDirectory string) error {
 	bitmapLoader := DirectoryBitmapLoader{}
-	bitmapLoader.LoadBitmapsFromDirectory(bitmapDirectory)
+
+	if err := bitmapLoader.LoadBitmapsFromDirectory(bitmapDirectory); err != nil {
+		return err
+	}
 
 	gd.Bitmaps = bitmapLoader.bitmaps
 	gd.TileNames = bitmapLoader.Keys()
@@ -71,6 +119,8 @@ func (gd *GameData) loadBitmaps(bitmapDirectory string) {
 	sort.SliceStable(gd.TileNames, func(i, j int) bool {
 		return gd.TileNames[i] < gd.TileNames[j]
 	})
+
+	return nil
 }
 
 func (gd *GameData) compileReferenceTiles() {
@@ -118,6 +168,7 @@ func (gd *GameData) compileReferenceTiles() {
 		rtg := gd.ReferenceTileGroups[tileName]
 
 		compileAvgFeaturePositions(rtg)
+		compileAdjacentFeatures(rtg)
 
 		for i := 0; i < 4; i++ {
 			rt := rtg.Orientations[i]
@@ -142,6 +193,101 @@ func compileAvgFeaturePositions(rtg *tile.ReferenceTileGroup) {
 	}
 }
 
+func compileAdjacentFeatures(rtg *tile.ReferenceTileGroup) {
+	for _, rt := range rtg.Orientations {
+		rt.AdjacentFeatures = adjacentFeatures(rt.Features, rt.FeatureMatrix)
+	}
+}
+
+// adjacentFeatures
+// features are adjacent if their pixels touch, or if they touch the same border of blank pixels,
+// which is how castle walls are drawn between a castle and the farm next to it
+func adjacentFeatures(features []*tile.Feature, m *matrix.Matrix[*tile.Feature]) map[*tile.Feature][]*tile.Feature {
+	adjacencySets := make(map[*tile.Feature]map[*tile.Feature]struct{}, len(features))
+	for _, f := range features {
+		adjacencySets[f] = make(map[*tile.Feature]struct{})
+	}
+
+	addAdjacent := func(touching []*tile.Feature) {
+		for _, a := range touching {
+			for _, b := range touching {
+				if a != b {
+					adjacencySets[a][b] = struct{}{}
+				}
+			}
+		}
+	}
+
+	visitedBorder := make(map[util.Point[int]]struct{})
+
+	m.Iterate(func(mf *tile.Feature, x int, y int, idx int) {
+		pt := util.Point[int]{X: x, Y: y}
+
+		if mf != nil {
+			for _, n := range pt.Neighbours() {
+				if nf, err := m.GetPt(n); err == nil && nf != nil && nf != mf {
+					addAdjacent([]*tile.Feature{mf, nf})
+				}
+			}
+			return
+		}
+
+		if _, exists := visitedBorder[pt]; exists {
+			return
+		}
+
+		//fill the border, collecting every feature which touches it
+		touching := make([]*tile.Feature, 0, 4)
+		stack := []util.Point[int]{pt}
+		visitedBorder[pt] = struct{}{}
+
+		var p util.Point[int]
+		for len(stack) > 0 {
+			p, stack = stack[len(stack)-1], stack[:len(stack)-1]
+
+			for _, n := range p.Neighbours() {
+				nf, err := m.GetPt(n)
+
+				if err != nil {
+					continue
+				}
+
+				if nf != nil {
+					touching = append(touching, nf)
+					continue
+				}
+
+				if _, exists := visitedBorder[n]; !exists {
+					visitedBorder[n] = struct{}{}
+					stack = append(stack, n)
+				}
+			}
+		}
+
+		addAdjacent(touching)
+	})
+
+	//keep the same order as the features list, for determinism
+	adjacent := make(map[*tile.Feature][]*tile.Feature, len(features))
+	for _, f := range features {
+		if _, exists := adjacent[f]; exists {
+			continue
+		}
+
+		adjacent[f] = make([]*tile.Feature, 0, len(adjacencySets[f]))
+		for _, af := range features {
+			if _, exists := adjacencySets[f][af]; exists {
+				adjacent[f] = append(adjacent[f], af)
+
+				//a river split by a road is listed more than once
+				delete(adjacencySets[f], af)
+			}
+		}
+	}
+
+	return adjacent
+}
+
 func avgFeaturePos(f *tile.Feature, m *matrix.Matrix[*tile.Feature]) util.Point[float64] {
 	var totalX int
 	var totalY int
@@ -230,6 +376,72 @@ func isShieldColor(c color.Color) bool {
 	return r == riverR && g == riverG && b == riverB && a == riverA
 }
 
+func isInnColor(c color.Color) bool {
+	r, g, b, a := c.RGBA()
+	innR, innG, innB, innA := color.RGBA{R: 223, G: 113, B: 38, A: 255}.RGBA()
+	return r == innR && g == innG && b == innB && a == innA
+}
+
+func isCathedralColor(c color.Color) bool {
+	r, g, b, a := c.RGBA()
+	cathedralR, cathedralG, cathedralB, cathedralA := color.RGBA{R: 155, G: 173, B: 183, A: 255}.RGBA()
+	return r == cathedralR && g == cathedralG && b == cathedralB && a == cathedralA
+}
+
+func isWineColor(c color.Color) bool {
+	r, g, b, a := c.RGBA()
+	wineR, wineG, wineB, wineA := color.RGBA{R: 118, G: 66, B: 138, A: 255}.RGBA()
+	return r == wineR && g == wineG && b == wineB && a == wineA
+}
+
+func isGrainColor(c color.Color) bool {
+	r, g, b, a := c.RGBA()
+	grainR, grainG, grainB, grainA := color.RGBA{R: 251, G: 242, B: 54, A: 255}.RGBA()
+	return r == grainR && g == grainG && b == grainB && a == grainA
+}
+
+func isClothColor(c color.Color) bool {
+	r, g, b, a := c.RGBA()
+	clothR, clothG, clothB, clothA := color.RGBA{R: 95, G: 205, B: 228, A: 255}.RGBA()
+	return r == clothR && g == clothG && b == clothB && a == clothA
+}
+
+func isVolcanoColor(c color.Color) bool {
+	r, g, b, a := c.RGBA()
+	volcanoR, volcanoG, volcanoB, volcanoA := color.RGBA{R: 217, G: 87, B: 99, A: 255}.RGBA()
+	return r == volcanoR && g == volcanoG && b == volcanoB && a == volcanoA
+}
+
+func isDragonColor(c color.Color) bool {
+	r, g, b, a := c.RGBA()
+	dragonR, dragonG, dragonB, dragonA := color.RGBA{R: 75, G: 105, B: 47, A: 255}.RGBA()
+	return r == dragonR && g == dragonG && b == dragonB && a == dragonA
+}
+
+func isPrincessColor(c color.Color) bool {
+	r, g, b, a := c.RGBA()
+	princessR, princessG, princessB, princessA := color.RGBA{R: 215, G: 123, B: 186, A: 255}.RGBA()
+	return r == princessR && g == princessG && b == princessB && a == princessA
+}
+
+func isPortalColor(c color.Color) bool {
+	r, g, b, a := c.RGBA()
+	portalR, portalG, portalB, portalA := color.RGBA{R: 48, G: 96, B: 130, A: 255}.RGBA()
+	return r == portalR && g == portalG && b == portalB && a == portalA
+}
+
+func isGardenColor(c color.Color) bool {
+	r, g, b, a := c.RGBA()
+	gardenR, gardenG, gardenB, gardenA := color.RGBA{R: 153, G: 229, B: 80, A: 255}.RGBA()
+	return r == gardenR && g == gardenG && b == gardenB && a == gardenA
+}
+
+func isTowerColor(c color.Color) bool {
+	r, g, b, a := c.RGBA()
+	towerR, towerG, towerB, towerA := color.RGBA{R: 132, G: 126, B: 135, A: 255}.RGBA()
+	return r == towerR && g == towerG && b == towerB && a == towerA
+}
+
 func (gd *GameData) buildMatrix(img image.Image) (*matrix.Matrix[*tile.Feature], []*tile.Feature) {
 
 	featureMatrix := matrix.NewMatrix[*tile.Feature](img.Bounds().Dx())
@@ -269,6 +481,28 @@ func (gd *GameData) buildMatrix(img image.Image) (*matrix.Matrix[*tile.Feature],
 			feature.Type = tile.Castle
 		} else if isShieldColor(featureColor) {
 			feature.Type = tile.Shield
+		} else if isInnColor(featureColor) {
+			feature.Type = tile.Inn
+		} else if isCathedralColor(featureColor) {
+			feature.Type = tile.Cathedral
+		} else if isWineColor(featureColor) {
+			feature.Type = tile.Wine
+		} else if isGrainColor(featureColor) {
+			feature.Type = tile.Grain
+		} else if isClothColor(featureColor) {
+			feature.Type = tile.Cloth
+		} else if isVolcanoColor(featureColor) {
+			feature.Type = tile.Volcano
+		} else if isDragonColor(featureColor) {
+			feature.Type = tile.Dragon
+		} else if isPrincessColor(featureColor) {
+			feature.Type = tile.Princess
+		} else if isPortalColor(featureColor) {
+			feature.Type = tile.Portal
+		} else if isGardenColor(featureColor) {
+			feature.Type = tile.Garden
+		} else if isTowerColor(featureColor) {
+			feature.Type = tile.Tower
 		} else if isCloisterColor(featureColor) {
 			feature.Type = tile.Cloister
 		} else {
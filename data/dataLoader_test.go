@@ -0,0 +1,46 @@
+package data_test
+
+import (
+	"beeb/carcassonne/data"
+	"beeb/carcassonne/engine/tile"
+	"fmt"
+	"sort"
+	"testing"
+)
+
+func TestLoadGameData_AdjacentFeatures(t *testing.T) {
+	gameData, err := data.LoadGameData("./bitmaps", "./standard_deck.yml")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//the shield sits inside the castle, and the castle wall is between the castle and the field
+	expected := map[tile.FeatureType]string{
+		tile.Castle: fmt.Sprint([]tile.FeatureType{tile.Farm, tile.Shield}),
+		tile.Farm:   fmt.Sprint([]tile.FeatureType{tile.Castle}),
+		tile.Shield: fmt.Sprint([]tile.FeatureType{tile.Castle}),
+	}
+
+	for _, rt := range gameData.ReferenceTileGroups["CastleCornerShield"].Orientations {
+		if len(rt.Features) != len(expected) {
+			t.Fatalf("%d degrees: %d features, expected %d", rt.Orientation, len(rt.Features), len(expected))
+		}
+
+		for _, f := range rt.Features {
+			adjacentTypes := make([]tile.FeatureType, 0, 2)
+
+			for _, af := range rt.AdjacentFeatures[f] {
+				adjacentTypes = append(adjacentTypes, af.Type)
+			}
+
+			sort.Slice(adjacentTypes, func(i, j int) bool {
+				return adjacentTypes[i] < adjacentTypes[j]
+			})
+
+			if s := fmt.Sprint(adjacentTypes); s != expected[f.Type] {
+				t.Errorf("%d degrees: %s is next to %s, expected %s", rt.Orientation, f.Type, s, expected[f.Type])
+			}
+		}
+	}
+}
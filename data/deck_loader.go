@@ -1,6 +1,7 @@
 package data
 
 import (
+	"fmt"
 	"os"
 
 	"gopkg.in/yaml.v2"
@@ -19,7 +20,13 @@ func LoadDeckInfo(deckFilePath string) (DeckInfo, error) {
 	err = yaml.Unmarshal(fileContent, &info)
 
 	if err != nil {
-		return info, err
+		return info, fmt.Errorf("%w: %v", ErrInvalidDeck, err)
+	}
+
+	for tileName, count := range info.Deck {
+		if count < 0 {
+			return info, fmt.Errorf("%w: %d of tile %s", ErrInvalidDeck, count, tileName)
+		}
 	}
 
 	return info, nil
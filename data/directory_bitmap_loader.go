@@ -38,11 +38,14 @@ func (dbl *DirectoryBitmapLoader) GetTileBitmap(tileName string) (image.Image, e
 	return nil, fmt.Errorf("there is no bitmap with the name %s", tileName)
 }
 
-func (dbl *DirectoryBitmapLoader) LoadBitmapsFromDirectory(bitmapDir string) {
+// LoadBitmapsFromDirectory
+// loads every bitmap in the directory, named after its file. nothing is loaded if any of them can't be,
+// the error is a *LoadError for the file which failed
+func (dbl *DirectoryBitmapLoader) LoadBitmapsFromDirectory(bitmapDir string) error {
 	files, err := ioutil.ReadDir(bitmapDir)
 
 	if err != nil {
-		panic(err)
+		return &LoadError{Path: bitmapDir, Err: err}
 	}
 
 	bitmaps := make(map[string]image.Image)
@@ -55,17 +58,20 @@ func (dbl *DirectoryBitmapLoader) LoadBitmapsFromDirectory(bitmapDir string) {
 		reader, err := os.Open(fileName)
 
 		if err != nil {
-			panic(err)
+			return &LoadError{Path: fileName, Err: err}
 		}
 
 		image, err := bmp.Decode(reader)
+		reader.Close()
 
 		if err != nil {
-			panic(err)
+			return &LoadError{Path: fileName, Err: fmt.Errorf("%w: %v", ErrInvalidBitmap, err)}
 		}
 
 		bitmaps[tileName] = image
 	}
 
 	dbl.bitmaps = bitmaps
+
+	return nil
 }
@@ -0,0 +1,248 @@
+package data
+
+import (
+	"beeb/carcassonne/engine/tile"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"gopkg.in/yaml.v2"
+)
+
+// RuleSet
+// the rules a game is played by, so house rules and variants can be played without changing any code.
+// scores are keyed by feature type name, and are what each tile of the feature is worth
+type RuleSet struct {
+	MaxMeeples int `yaml:"maxMeeples"`
+
+	//big meeples are given to each player along with their normal meeples, they count as two when working out who owns a feature
+	BigMeeples int `yaml:"bigMeeples"`
+
+	//builders and pigs join features the player already has a meeple on, they're given to each player like big meeples
+	Builders int `yaml:"builders"`
+	Pigs     int `yaml:"pigs"`
+
+	//an abbot goes in a cloister or garden, and can be taken back before it's finished for what it's worth so far
+	Abbots int `yaml:"abbots"`
+
+	//what a pig adds to its field, for each finished castle the field borders
+	PigBonus int `yaml:"pigBonus"`
+
+	//awarded at the end of the game to the players with the most of each kind of goods
+	GoodsBonus int `yaml:"goodsBonus"`
+
+	//the fairy is moved next to one of the player's followers instead of placing a meeple, and keeps the dragon away from it.
+	//the follower's owner scores the turn bonus at the start of each of their turns, and the fairy bonus when the follower's feature is scored
+	Fairy          bool `yaml:"fairy"`
+	FairyTurnBonus int  `yaml:"fairyTurnBonus"`
+	FairyBonus     int  `yaml:"fairyBonus"`
+
+	//tower floors are given to each player, they're built on towers instead of placing a meeple to capture meeples in reach of the tower.
+	//a captured meeple's owner pays the ransom to the player holding it to get it back
+	TowerFloors int `yaml:"towerFloors"`
+	Ransom      int `yaml:"ransom"`
+
+	//how many tiles the dragon moves when a tile with a dragon on it is placed
+	DragonMoves int `yaml:"dragonMoves"`
+
+	//how many tiles are drawn looking for one which can be placed, before a tile is thrown out
+	DrawAttempts int `yaml:"drawAttempts"`
+
+	//each player holds a hand of this many tiles from the main deck, and picks which one to play.
+	//0 plays the usual way, with the one tile drawn each turn
+	HandSize int `yaml:"handSize"`
+
+	//which of the river variants is played at the start of the game, the classic river when it's left out
+	River string `yaml:"river"`
+
+	//a curving river must turn the other way to the last curve, so it doesn't loop back on itself
+	RiverAlternatingTurns bool `yaml:"riverAlternatingTurns"`
+
+	//start the game in one of the board's quadrants, facing inwards, rather than in the middle of the board
+	QuadrantStart bool `yaml:"quadrantStart"`
+
+	Scores map[string]int `yaml:"scores"`
+
+	//what unfinished features are worth at the end of the game
+	EndGameScores map[string]int `yaml:"endGameScores"`
+}
+
+var ErrInvalidRuleSet = errors.New("invalid rule set")
+
+// the feature types every rule set has a score and an end game score for, the rest aren't scored
+var scoredFeatureTypes = []tile.FeatureType{
+	tile.Farm, tile.Road, tile.Castle, tile.Cloister, tile.Garden, tile.Shield, tile.Inn, tile.Cathedral,
+}
+
+// the river variants a game can be played with
+const (
+	NoRiver      = "none"
+	ClassicRiver = "classic"
+	RiverII      = "river2"
+)
+
+func DefaultRuleSet() *RuleSet {
+	rules := &RuleSet{
+		MaxMeeples:            7,
+		DrawAttempts:          3,
+		River:                 ClassicRiver,
+		RiverAlternatingTurns: true,
+		QuadrantStart:         true,
+		PigBonus:              1,
+		GoodsBonus:            10,
+		FairyTurnBonus:        1,
+		FairyBonus:            3,
+		DragonMoves:           6,
+		Ransom:                3,
+		Scores:                make(map[string]int),
+		EndGameScores:         make(map[string]int),
+	}
+
+	for _, ft := range tile.FeatureTypes() {
+		rules.Scores[ft.String()] = ft.Score()
+		rules.EndGameScores[ft.String()] = ft.EndGameScore()
+	}
+
+	return rules
+}
+
+// LoadRuleSet
+// reads a rule set from a yaml file, anything the file leaves out keeps its default.
+// the errors returned are a *LoadError, a key which isn't a rule, like a typo, is an error rather than being left out
+func LoadRuleSet(ruleSetFilePath string) (*RuleSet, error) {
+	rules := DefaultRuleSet()
+
+	fileContent, err := os.ReadFile(ruleSetFilePath)
+
+	if err != nil {
+		return nil, &LoadError{Path: ruleSetFilePath, Err: err}
+	}
+
+	//a strict read won't set a score the defaults already have, so the file's scores are read on their own, then laid over the defaults
+	scores, endGameScores := rules.Scores, rules.EndGameScores
+	rules.Scores, rules.EndGameScores = nil, nil
+
+	err = yaml.UnmarshalStrict(fileContent, rules)
+
+	if err != nil {
+		return nil, &LoadError{Path: ruleSetFilePath, Err: fmt.Errorf("%w: %v", ErrInvalidRuleSet, err)}
+	}
+
+	rules.Scores = overlayScores(scores, rules.Scores)
+	rules.EndGameScores = overlayScores(endGameScores, rules.EndGameScores)
+
+	if err = rules.Validate(); err != nil {
+		return nil, &LoadError{Path: ruleSetFilePath, Err: err}
+	}
+
+	return rules, nil
+}
+
+func overlayScores(scores map[string]int, overlay map[string]int) map[string]int {
+	for name, score := range overlay {
+		scores[name] = score
+	}
+
+	return scores
+}
+
+// Validate
+// checks none of the counts, bonuses or scores are negative, and there's a score and an end game score
+// for each feature type which is scored. the error names the key which is wrong
+func (rules *RuleSet) Validate() error {
+	counts := []struct {
+		key   string
+		value int
+	}{
+		{"maxMeeples", rules.MaxMeeples},
+		{"bigMeeples", rules.BigMeeples},
+		{"builders", rules.Builders},
+		{"pigs", rules.Pigs},
+		{"abbots", rules.Abbots},
+		{"pigBonus", rules.PigBonus},
+		{"goodsBonus", rules.GoodsBonus},
+		{"fairyTurnBonus", rules.FairyTurnBonus},
+		{"fairyBonus", rules.FairyBonus},
+		{"towerFloors", rules.TowerFloors},
+		{"ransom", rules.Ransom},
+		{"dragonMoves", rules.DragonMoves},
+	}
+
+	for _, c := range counts {
+		if c.value < 0 {
+			return fmt.Errorf("%w: %s must not be negative", ErrInvalidRuleSet, c.key)
+		}
+	}
+
+	switch rules.River {
+	case "", NoRiver, ClassicRiver, RiverII:
+	default:
+		return fmt.Errorf("%w: unknown river variant %s", ErrInvalidRuleSet, rules.River)
+	}
+
+	if rules.DrawAttempts < 1 {
+		return fmt.Errorf("%w: drawAttempts must be at least 1", ErrInvalidRuleSet)
+	}
+
+	if rules.HandSize < 0 {
+		return fmt.Errorf("%w: handSize must not be negative", ErrInvalidRuleSet)
+	}
+
+	scoreSets := []struct {
+		key    string
+		scores map[string]int
+	}{
+		{"scores", rules.Scores},
+		{"endGameScores", rules.EndGameScores},
+	}
+
+	for _, s := range scoreSets {
+		names := make([]string, 0, len(s.scores))
+
+		for name := range s.scores {
+			names = append(names, name)
+		}
+
+		//sorted, so the same file always gives the same error
+		sort.Strings(names)
+
+		for _, name := range names {
+			if _, exists := tile.ParseFeatureType(name); !exists {
+				return fmt.Errorf("%w: %s.%s is not a feature type", ErrInvalidRuleSet, s.key, name)
+			}
+
+			if s.scores[name] < 0 {
+				return fmt.Errorf("%w: %s.%s must not be negative", ErrInvalidRuleSet, s.key, name)
+			}
+		}
+
+		for _, ft := range scoredFeatureTypes {
+			if _, exists := s.scores[ft.String()]; !exists {
+				return fmt.Errorf("%w: %s.%s is missing", ErrInvalidRuleSet, s.key, ft)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Supply what the rule set gives every player at the start of the game
+func (rules *RuleSet) Supply() MeepleSupply {
+	return MeepleSupply{
+		Followers:   rules.MaxMeeples,
+		BigMeeples:  rules.BigMeeples,
+		Builders:    rules.Builders,
+		Pigs:        rules.Pigs,
+		Abbots:      rules.Abbots,
+		TowerFloors: rules.TowerFloors,
+	}
+}
+
+func (rules *RuleSet) Score(ft tile.FeatureType) int {
+	return rules.Scores[ft.String()]
+}
+
+func (rules *RuleSet) EndGameScore(ft tile.FeatureType) int {
+	return rules.EndGameScores[ft.String()]
+}
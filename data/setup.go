@@ -0,0 +1,129 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"os"
+
+	"gopkg.in/yaml.v2"
+)
+
+// GameSetup
+// who's playing a game, in turn order, so games with any number of players can be set up without changing any code
+type GameSetup struct {
+	Players []PlayerSetup `yaml:"players"`
+}
+
+// PlayerSetup
+// one player's seat at the table. anything left out is filled in by the engine, a player with no name is called
+// Player and their seat number, one with no colour gets one from the engine's palette, and one with no AI gets the basic AI
+type PlayerSetup struct {
+	Name string `yaml:"name"`
+
+	//a hex colour, like #e53935
+	Color string `yaml:"color"`
+
+	//one of the engine's built-in AIs, by name
+	AI string `yaml:"ai"`
+
+	//the player's own supply, instead of the one the rule set gives every player
+	Meeples *MeepleSupply `yaml:"meeples"`
+}
+
+// MeepleSupply
+// what a player starts the game with. a supply given in a setup file replaces the rule set's,
+// so anything it leaves out the player doesn't get
+type MeepleSupply struct {
+	Followers   int `yaml:"followers"`
+	BigMeeples  int `yaml:"bigMeeples"`
+	Builders    int `yaml:"builders"`
+	Pigs        int `yaml:"pigs"`
+	Abbots      int `yaml:"abbots"`
+	TowerFloors int `yaml:"towerFloors"`
+}
+
+var ErrInvalidGameSetup = errors.New("invalid game setup")
+
+// DefaultGameSetup a game for the given number of players, who are all filled in by the engine
+func DefaultGameSetup(numPlayers int) *GameSetup {
+	return &GameSetup{
+		Players: make([]PlayerSetup, numPlayers),
+	}
+}
+
+// LoadGameSetup reads the players for a game from a yaml file
+func LoadGameSetup(setupFilePath string) (*GameSetup, error) {
+	setup := &GameSetup{}
+
+	fileContent, err := os.ReadFile(setupFilePath)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = yaml.Unmarshal(fileContent, setup)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err = setup.Validate(); err != nil {
+		return nil, err
+	}
+
+	return setup, nil
+}
+
+// Validate
+// checks the players' colours and supplies, the engine checks their AIs, as it's the one which knows them.
+// no two players can choose the same colour
+func (setup *GameSetup) Validate() error {
+	if len(setup.Players) < 1 {
+		return fmt.Errorf("%w: there must be at least one player", ErrInvalidGameSetup)
+	}
+
+	colors := make(map[color.RGBA]int, len(setup.Players))
+
+	for i, ps := range setup.Players {
+		if ps.Color != "" {
+			c, err := ParseColor(ps.Color)
+
+			if err != nil {
+				return fmt.Errorf("%w: player %d: %v", ErrInvalidGameSetup, i, err)
+			}
+
+			if other, exists := colors[c]; exists {
+				return fmt.Errorf("%w: player %d: %s is already player %d's colour", ErrInvalidGameSetup, i, ps.Color, other)
+			}
+
+			colors[c] = i
+		}
+
+		if s := ps.Meeples; s != nil {
+			if s.Followers < 0 || s.BigMeeples < 0 || s.Builders < 0 || s.Pigs < 0 || s.Abbots < 0 || s.TowerFloors < 0 {
+				return fmt.Errorf("%w: player %d: meeple supply must not be negative", ErrInvalidGameSetup, i)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ParseColor reads a hex colour, like #e53935, as an opaque colour
+func ParseColor(hex string) (color.RGBA, error) {
+	c := color.RGBA{A: 255}
+
+	if _, err := fmt.Sscanf(hex, "#%02x%02x%02x", &c.R, &c.G, &c.B); err != nil || len(hex) != 7 {
+		return color.RGBA{}, fmt.Errorf("%s is not a hex colour like #e53935", hex)
+	}
+
+	return c, nil
+}
+
+// FormatColor writes a colour in hex, the opposite of ParseColor
+func FormatColor(c color.Color) string {
+	r, g, b, _ := c.RGBA()
+
+	return fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8)
+}
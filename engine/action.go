@@ -0,0 +1,477 @@
+package engine
+
+import (
+	"beeb/carcassonne/engine/tile"
+	"beeb/carcassonne/engine/turnStage"
+	"beeb/carcassonne/util"
+)
+
+// Action
+// everything a player decides on their turn: where the held tile goes, which way it's facing,
+// and which of its features to place a meeple on, if any
+type Action struct {
+	Position      util.Point[int]
+	ReferenceTile *tile.ReferenceTile
+	//a feature of the reference tile, nil when no meeple is placed
+	MeepleFeature *tile.Feature
+	//the type and power of the meeple placed, like a follower with a power of 2 for a big meeple.
+	//a normal follower is placed when they're left as 0
+	MeepleType  MeepleType
+	MeeplePower int
+	//set when the meeple goes through the tile's magic portal, MeepleFeature is then a feature of the tile at this position
+	PortalPosition *util.Point[int]
+
+	//instead of placing a meeple, the princess on the tile can send a follower in her castle home,
+	//the fairy can be moved next to one of the player's followers, the player's abbot can be recalled,
+	//or a floor can be built on the tower at TowerPosition, capturing CapturedMeeple if it's set
+	PrincessMeeple *Meeple
+	FairyMeeple    *Meeple
+	RecalledAbbot  *Meeple
+	TowerPosition  *util.Point[int]
+	CapturedMeeple *Meeple
+}
+
+func NewAction(placement Placement, meeplePlacement *MeeplePlacement) Action {
+	action := Action{
+		Position:      placement.Position,
+		ReferenceTile: placement.ReferenceTile,
+	}
+
+	if meeplePlacement != nil && meeplePlacement.SelectedMeeple != nil {
+		action.MeepleFeature = meeplePlacement.ParentFeature
+		action.MeepleType = meeplePlacement.SelectedMeeple.Type
+		action.MeeplePower = meeplePlacement.SelectedMeeple.Power
+
+		if meeplePlacement.PortalPosition != nil {
+			pos := *meeplePlacement.PortalPosition
+			action.PortalPosition = &pos
+		}
+	}
+
+	if meeplePlacement != nil {
+		action.PrincessMeeple = meeplePlacement.PrincessMeeple
+		action.FairyMeeple = meeplePlacement.FairyMeeple
+		action.RecalledAbbot = meeplePlacement.RecalledAbbot
+		action.CapturedMeeple = meeplePlacement.CapturedMeeple
+
+		if meeplePlacement.TowerPosition != nil {
+			pos := *meeplePlacement.TowerPosition
+			action.TowerPosition = &pos
+		}
+	}
+
+	return action
+}
+
+func (a Action) PlacesMeeple() bool {
+	return a.MeepleFeature != nil
+}
+
+// decidesMeeple
+// whether the action places a meeple, or uses the princess, the fairy, the abbot or a tower instead
+func (a Action) decidesMeeple() bool {
+	return a.PlacesMeeple() || a.PrincessMeeple != nil || a.FairyMeeple != nil || a.RecalledAbbot != nil ||
+		a.TowerPosition != nil || a.CapturedMeeple != nil
+}
+
+func (a Action) meeplePower() int {
+	if a.MeeplePower < 1 {
+		return 1
+	}
+
+	return a.MeeplePower
+}
+
+func (a Action) Placement() Placement {
+	return Placement{
+		Position:      a.Position,
+		ReferenceTile: a.ReferenceTile,
+	}
+}
+
+func (a Action) matchesPlacement(placement Placement) bool {
+	return a.Position == placement.Position && a.ReferenceTile == placement.ReferenceTile
+}
+
+// LegalActions
+// every action the current player can take with the tile they're holding,
+// each possible tile placement with no meeple, and with each kind of meeple they have on each feature it can legally go on,
+// including through a magic portal. then each follower the princess can send home, each follower the fairy can be moved to,
+// each abbot the player can recall, and each tower they can build on with each meeple it can capture
+func (e *Engine) LegalActions() []Action {
+	if e.GameOver || e.TurnStage != turnStage.PlaceTile {
+		return nil
+	}
+
+	return e.legalActions(e.CurrentPossibleTilePlacements)
+}
+
+// LegalPlacementActions
+// the current player's legal actions which put the held tile down with the placement, see LegalActions.
+// it's much quicker than looking through all of them, when only a few placements are of interest
+func (e *Engine) LegalPlacementActions(placement Placement) []Action {
+	if e.GameOver || e.TurnStage != turnStage.PlaceTile || !e.isLegalPlacement(NewAction(placement, nil)) {
+		return nil
+	}
+
+	return e.legalActions([]Placement{placement})
+}
+
+func (e *Engine) legalActions(placements []Placement) []Action {
+	actions := make([]Action, 0, len(placements)*2)
+	meepleKinds := e.CurrentPlayer().availableMeepleKinds()
+	fairyMeeples := e.legalFairyMeeples()
+	recalledAbbots := e.legalRecalledAbbots()
+
+	for _, placement := range placements {
+		noMeeple := NewAction(placement, nil)
+		actions = append(actions, noMeeple)
+
+		for _, m := range meepleKinds {
+			action := noMeeple
+			action.MeepleType = m.Type
+			action.MeeplePower = m.Power
+
+			for _, f := range e.legalMeepleFeatures(placement, m) {
+				action.MeepleFeature = f
+				actions = append(actions, action)
+			}
+
+			for _, target := range e.legalPortalTargets(placement, m) {
+				pos := target.position
+				action.MeepleFeature = target.feature
+				action.PortalPosition = &pos
+				actions = append(actions, action)
+			}
+		}
+
+		for _, m := range e.legalPrincessMeeples(placement) {
+			action := noMeeple
+			action.PrincessMeeple = m
+			actions = append(actions, action)
+		}
+
+		for _, m := range fairyMeeples {
+			action := noMeeple
+			action.FairyMeeple = m
+			actions = append(actions, action)
+		}
+
+		for _, m := range recalledAbbots {
+			action := noMeeple
+			action.RecalledAbbot = m
+			actions = append(actions, action)
+		}
+
+		for _, capture := range e.legalTowerCaptures(placement) {
+			pos := capture.position
+			action := noMeeple
+			action.TowerPosition = &pos
+			action.CapturedMeeple = capture.meeple
+			actions = append(actions, action)
+		}
+	}
+
+	return actions
+}
+
+// Apply
+// plays the action as the current player's turn, then steps the engine on to the next player's decision
+func (e *Engine) Apply(action Action) error {
+	if e.GameOver || e.TurnStage != turnStage.PlaceTile {
+		return ErrActionOutOfTurn
+	}
+
+	action = e.gameAction(action)
+
+	if err := e.ValidateAction(action); err != nil {
+		return err
+	}
+
+	e.pendingAction = &action
+
+	//finish this turn, the other players may still have to decide where the dragon goes
+	for !e.GameOver && e.TurnStage != turnStage.Draw {
+		if err := e.Step(); err != nil {
+			return err
+		}
+	}
+
+	return e.StepUntilDecision()
+}
+
+// StepUntilDecision
+// steps the engine until a player has to decide on an action, or the game ends.
+// it stops at the first error, like a player who can't decide where the dragon goes
+func (e *Engine) StepUntilDecision() error {
+	for !e.GameOver && e.TurnStage != turnStage.PlaceTile {
+		if err := e.Step(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ValidateAction
+// checks the action is one of the current player's legal actions
+func (e *Engine) ValidateAction(action Action) error {
+	if !e.isLegalPlacement(action) {
+		return ErrIllegalTilePlacement
+	}
+
+	action = e.gameAction(action)
+
+	return e.validateActionMeeple(action, e.meeplePlacementForAction(action))
+}
+
+// validateActionMeeple
+// checks the meeple placement the action decides on can be made with the action's tile
+func (e *Engine) validateActionMeeple(action Action, mp *MeeplePlacement) error {
+	if mp == nil {
+		return nil
+	}
+
+	if action.PlacesMeeple() && mp.SelectedMeeple == nil {
+		return &InvalidMeeplePlacementError{
+			Player:    e.CurrentPlayer(),
+			Placement: mp,
+			Err:       ErrNoMeepleAvailable,
+		}
+	}
+
+	var err error
+	e.withHypotheticalTile(action.Placement(), func(t *tile.Tile) {
+		err = e.validateMeepleDecision(t, mp)
+	})
+
+	return err
+}
+
+// gameAction
+// the action with its meeples swapped for the game's own, so an action can be made from copies of them,
+// like those in the legal actions an observation hands out
+func (e *Engine) gameAction(action Action) Action {
+	action.PrincessMeeple = e.gameMeeple(action.PrincessMeeple)
+	action.FairyMeeple = e.gameMeeple(action.FairyMeeple)
+	action.RecalledAbbot = e.gameMeeple(action.RecalledAbbot)
+	action.CapturedMeeple = e.gameMeeple(action.CapturedMeeple)
+
+	return action
+}
+
+// gameMeeple
+// the game's own meeple with the same id as m. a meeple which isn't in the game is left as it is, so it fails validation
+func (e *Engine) gameMeeple(m *Meeple) *Meeple {
+	if m == nil {
+		return nil
+	}
+
+	for _, p := range e.Players {
+		for _, pm := range p.Meeples {
+			if pm.Id == m.Id {
+				return pm
+			}
+		}
+	}
+
+	return m
+}
+
+// isLegalPlacement
+// checks the action places the held tile in one of the possible placements, the meeple isn't checked
+func (e *Engine) isLegalPlacement(action Action) bool {
+	for _, placement := range e.CurrentPossibleTilePlacements {
+		if action.matchesPlacement(placement) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// the engine picks which of the player's meeples is placed, an AI's decision keeps the meeple it picked
+func (e *Engine) meeplePlacementForAction(action Action) *MeeplePlacement {
+	if !action.decidesMeeple() {
+		return nil
+	}
+
+	mp := &MeeplePlacement{
+		PrincessMeeple: action.PrincessMeeple,
+		FairyMeeple:    action.FairyMeeple,
+		RecalledAbbot:  action.RecalledAbbot,
+		TowerPosition:  action.TowerPosition,
+		CapturedMeeple: action.CapturedMeeple,
+	}
+
+	if !action.PlacesMeeple() {
+		return mp
+	}
+
+	selectedMeeple := e.CurrentPlayer().GetAvailableMeepleOfType(action.MeepleType)
+
+	if action.MeepleType == Follower {
+		selectedMeeple = e.CurrentPlayer().GetAvailableMeepleWithPower(action.meeplePower())
+	}
+
+	mp.ParentFeature = action.MeepleFeature
+	mp.SelectedMeeple = selectedMeeple
+	mp.PortalPosition = action.PortalPosition
+
+	return mp
+}
+
+// the features of the placement's reference tile which the current player could place the meeple on
+func (e *Engine) legalMeepleFeatures(placement Placement, selectedMeeple *Meeple) []*tile.Feature {
+	if selectedMeeple == nil {
+		return nil
+	}
+
+	features := make([]*tile.Feature, 0, 4)
+
+	e.withHypotheticalTile(placement, func(t *tile.Tile) {
+		for _, f := range t.Features {
+			mp := &MeeplePlacement{
+				ParentFeature:  f.ParentFeature,
+				SelectedMeeple: selectedMeeple,
+			}
+
+			if _, err := e.ValidateMeeplePlacement(t, mp); err != nil {
+				continue
+			}
+
+			//a river split by a road shows up more than once
+			duplicate := false
+			for _, lf := range features {
+				if lf == f.ParentFeature {
+					duplicate = true
+					break
+				}
+			}
+
+			if !duplicate {
+				features = append(features, f.ParentFeature)
+			}
+		}
+	})
+
+	return features
+}
+
+type portalTarget struct {
+	position util.Point[int]
+	feature  *tile.Feature
+}
+
+// legalPortalTargets
+// the features of the tiles already on the board which the meeple could go on through the placement's magic portal,
+// as features of their reference tiles
+func (e *Engine) legalPortalTargets(placement Placement, selectedMeeple *Meeple) []portalTarget {
+	if selectedMeeple == nil || !placement.ReferenceTile.HasFeatureType(tile.Portal) {
+		return nil
+	}
+
+	targets := make([]portalTarget, 0, 16)
+
+	e.withHypotheticalTile(placement, func(t *tile.Tile) {
+		for _, bt := range e.GameBoard.PlacedTiles() {
+			if bt == t {
+				continue
+			}
+
+			pos := bt.Position
+
+			for _, f := range bt.Features {
+				mp := &MeeplePlacement{
+					ParentFeature:  f.ParentFeature,
+					SelectedMeeple: selectedMeeple,
+					PortalPosition: &pos,
+				}
+
+				if _, err := e.ValidateMeeplePlacement(t, mp); err != nil {
+					continue
+				}
+
+				//a river split by a road shows up more than once
+				duplicate := false
+				for _, target := range targets {
+					if target.position == pos && target.feature == f.ParentFeature {
+						duplicate = true
+						break
+					}
+				}
+
+				if !duplicate {
+					targets = append(targets, portalTarget{position: pos, feature: f.ParentFeature})
+				}
+			}
+		}
+	})
+
+	return targets
+}
+
+// legalPrincessMeeples
+// the followers the princess on the placement's tile could send home, in the order of the players and their meeples
+func (e *Engine) legalPrincessMeeples(placement Placement) []*Meeple {
+	if !placement.ReferenceTile.HasFeatureType(tile.Princess) {
+		return nil
+	}
+
+	meeples := make([]*Meeple, 0, 2)
+
+	e.withHypotheticalTile(placement, func(t *tile.Tile) {
+		for _, p := range e.Players {
+			for _, m := range p.Meeples {
+				mp := &MeeplePlacement{PrincessMeeple: m}
+
+				if _, err := e.validatePrincess(t, mp); err == nil {
+					meeples = append(meeples, m)
+				}
+			}
+		}
+	})
+
+	return meeples
+}
+
+// legalFairyMeeples the current player's followers the fairy could be moved next to
+func (e *Engine) legalFairyMeeples() []*Meeple {
+	if !e.Rules.Fairy {
+		return nil
+	}
+
+	meeples := make([]*Meeple, 0, 2)
+
+	for _, m := range e.CurrentPlayer().Meeples {
+		if err := e.validateFairy(&MeeplePlacement{FairyMeeple: m}); err == nil {
+			meeples = append(meeples, m)
+		}
+	}
+
+	return meeples
+}
+
+// legalRecalledAbbots the current player's abbots on the board
+func (e *Engine) legalRecalledAbbots() []*Meeple {
+	meeples := make([]*Meeple, 0, 1)
+
+	for _, m := range e.CurrentPlayer().Meeples {
+		if err := e.validateRecallAbbot(&MeeplePlacement{RecalledAbbot: m}); err == nil {
+			meeples = append(meeples, m)
+		}
+	}
+
+	return meeples
+}
+
+// withHypotheticalTile
+// places a tile on the board for the duration of fn, without any of the engine's bookkeeping
+func (e *Engine) withHypotheticalTile(placement Placement, fn func(t *tile.Tile)) {
+	t := e.TileFactory.NewTileFromReference(placement.ReferenceTile)
+	remove := e.GameBoard.PlaceHypotheticalTile(placement.Position, t)
+
+	fn(t)
+
+	remove()
+}
@@ -0,0 +1,125 @@
+package engine_test
+
+import (
+	"beeb/carcassonne/engine"
+	"beeb/carcassonne/engine/tile"
+	"beeb/carcassonne/engine/turnStage"
+	"beeb/carcassonne/util"
+	"errors"
+	"testing"
+)
+
+func TestEngine_Apply(t *testing.T) {
+
+	gameData := loadGameData(t, "../data/standard_deck.yml")
+	e := newEngine(t, gameData, 16, 4, 4)
+
+	if err := e.StepUntilDecision(); err != nil {
+		t.Fatal(err)
+	}
+
+	for !e.GameOver {
+		actions := e.LegalActions()
+
+		if len(actions) < 1 {
+			t.Fatal("expected legal actions while waiting on a decision")
+		}
+
+		//prefer placing meeples, to exercise their validation
+		action := actions[len(actions)-1]
+
+		if err := e.Apply(action); err != nil {
+			t.Fatalf("legal action was rejected: %v", err)
+		}
+
+		if e.MeeplePlacementError != nil {
+			t.Fatalf("legal action placed an illegal meeple: %v", e.MeeplePlacementError)
+		}
+	}
+
+	if err := e.Apply(engine.Action{}); !errors.Is(err, engine.ErrActionOutOfTurn) {
+		t.Errorf("Apply() after the game ended, error = %v, want %v", err, engine.ErrActionOutOfTurn)
+	}
+}
+
+func TestEngine_ApplyIllegalAction(t *testing.T) {
+	gameData := loadGameData(t, "../data/standard_deck.yml")
+	e := newEngine(t, gameData, 16, 4, 4)
+
+	if err := e.StepUntilDecision(); err != nil {
+		t.Fatal(err)
+	}
+
+	action := e.LegalActions()[0]
+	action.Position = action.Position.Add(util.Point[int]{X: 3, Y: 3})
+
+	if err := e.Apply(action); !errors.Is(err, engine.ErrIllegalTilePlacement) {
+		t.Errorf("Apply() error = %v, want %v", err, engine.ErrIllegalTilePlacement)
+	}
+
+	if e.GameBoard.PlacedTileCount != 0 {
+		t.Error("an illegal action should not change the board")
+	}
+}
+
+// meepleAI places its meeple on the first feature a follower can go on, whoever's meeple it is
+type meepleAI struct {
+	meeple *engine.Meeple
+}
+
+func (ai meepleAI) DeterminePlacement(o *engine.Observation, placementOptions []engine.Placement) (*engine.Placement, *engine.MeeplePlacement) {
+	for _, a := range o.LegalActions() {
+		if a.PlacesMeeple() && a.PortalPosition == nil && a.MeepleType == engine.Follower {
+			placement := a.Placement()
+
+			return &placement, &engine.MeeplePlacement{
+				ParentFeature:  a.MeepleFeature,
+				SelectedMeeple: ai.meeple,
+			}
+		}
+	}
+
+	return &placementOptions[0], nil
+}
+
+func TestEngine_StepIllegalMeeple(t *testing.T) {
+	gameData := loadGameData(t, "../data/standard_deck.yml")
+	e := newEngine(t, gameData, 16, 2, 4)
+
+	if err := e.StepUntilDecision(); err != nil {
+		t.Fatal(err)
+	}
+
+	player := e.CurrentPlayer()
+	other := e.Players[(e.CurrentPlayerIndex+1)%len(e.Players)]
+
+	//a corner of the board, out of the way of the held tile's placements
+	placed := placeTestMeeple(player, placeTestTile(e, "CastleEndCap", 0, 0, 0), tile.Castle)
+	placedTiles := e.GameBoard.PlacedTileCount
+
+	tests := []struct {
+		name   string
+		meeple *engine.Meeple
+		want   error
+	}{
+		{"another player's", other.GetAvailableMeepleWithPower(1), engine.ErrMeepleNotOwned},
+		{"already placed", placed, engine.ErrMeepleInUse},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			player.AI = meepleAI{meeple: tt.meeple}
+			err := e.Step()
+
+			var decisionErr *engine.DecisionError
+			var placementErr *engine.InvalidMeeplePlacementError
+			if !errors.As(err, &decisionErr) || !errors.As(err, &placementErr) || !errors.Is(err, tt.want) {
+				t.Fatalf("Step() with %s meeple, error = %v, want %v", tt.name, err, tt.want)
+			}
+
+			if e.TurnStage != turnStage.PlaceTile || e.GameBoard.PlacedTileCount != placedTiles {
+				t.Error("the turn should be left waiting on the player's decision")
+			}
+		})
+	}
+}
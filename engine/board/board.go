@@ -9,18 +9,36 @@ import (
 	"sort"
 )
 
+// Unbounded
+// the board size to pass to NewBoard for a board which grows in every direction without limit
+const Unbounded = 0
+
+// Board
+// tiles are kept in a sparse map keyed by position, so the board only costs as much as the tiles placed on it.
+// a bounded board only allows positions from 0 up to Size on each axis, an unbounded one allows any position,
+// including negative ones
 type Board struct {
-	TileMatrix        *matrix.Matrix[*tile.Tile]
+	Tiles             map[util.Point[int]]*tile.Tile
+	Size              int
 	PlacedTileCount   int
 	OpenPositions     map[util.Point[int]]*tile.EdgeSignature
 	openPositionsList []util.Point[int]
 	EdgePixReference  [][]util.Point[int]
+
+	//the smallest rectangle holding every placed tile
+	min util.Point[int]
+	max util.Point[int]
 }
 
 func NewBoard(size int) *Board {
 	board := &Board{}
 
-	board.TileMatrix = matrix.NewMatrix[*tile.Tile](size)
+	if size < 0 {
+		size = Unbounded
+	}
+
+	board.Size = size
+	board.Tiles = make(map[util.Point[int]]*tile.Tile, 128)
 	board.OpenPositions = make(map[util.Point[int]]*tile.EdgeSignature, 128)
 	board.openPositionsList = make([]util.Point[int], 0, 128)
 	board.EdgePixReference = edgePix(image.Rect(0, 0, 7, 7))
@@ -28,26 +46,213 @@ func NewBoard(size int) *Board {
 	return board
 }
 
+func (b *Board) Bounded() bool {
+	return b.Size != Unbounded
+}
+
+func (b *Board) IsInBounds(pos util.Point[int]) bool {
+	if !b.Bounded() {
+		return true
+	}
+
+	return pos.X >= 0 && pos.Y >= 0 && pos.X < b.Size && pos.Y < b.Size
+}
+
+// Get gets the tile placed at pos, or nil if there isn't one
+func (b *Board) Get(pos util.Point[int]) *tile.Tile {
+	return b.Tiles[pos]
+}
+
+// Center
+// the middle of a bounded board, or the origin of an unbounded one
+func (b *Board) Center() util.Point[int] {
+	return util.Point[int]{X: b.Size / 2, Y: b.Size / 2}
+}
+
+// Bounds
+// the top left and bottom right positions of the smallest rectangle holding every placed tile
+func (b *Board) Bounds() (util.Point[int], util.Point[int]) {
+	return b.min, b.max
+}
+
+// Matrix
+// converts the board to a dense matrix for things which need a fixed grid, like rendering and the ai link.
+// a bounded board is converted as a whole, an unbounded board is cropped to the smallest square holding every tile.
+// the position of the matrix's top left cell on the board is returned along with it
+func (b *Board) Matrix() (*matrix.Matrix[*tile.Tile], util.Point[int]) {
+	origin := util.Point[int]{}
+	size := b.Size
+
+	if !b.Bounded() {
+		origin = b.min
+		size = 0
+
+		if len(b.Tiles) > 0 {
+			size = b.max.X - b.min.X + 1
+
+			if h := b.max.Y - b.min.Y + 1; h > size {
+				size = h
+			}
+		}
+	}
+
+	m := matrix.NewMatrix[*tile.Tile](size)
+
+	for pos, t := range b.Tiles {
+		m.Set(pos.X-origin.X, pos.Y-origin.Y, t)
+	}
+
+	return m, origin
+}
+
+// Clone
+// deep copies the board and every tile on it. the map of copies is returned too, so whatever else
+// points at the original tiles and features can be pointed at the copies
+func (b *Board) Clone() (*Board, *tile.CloneMap) {
+	tiles := make([]*tile.Tile, 0, len(b.Tiles))
+
+	for _, t := range b.Tiles {
+		tiles = append(tiles, t)
+	}
+
+	cm := tile.CloneTiles(tiles)
+
+	cb := &Board{
+		Size:             b.Size,
+		PlacedTileCount:  b.PlacedTileCount,
+		EdgePixReference: b.EdgePixReference,
+		min:              b.min,
+		max:              b.max,
+	}
+
+	cb.Tiles = make(map[util.Point[int]]*tile.Tile, len(b.Tiles))
+
+	for pos, t := range b.Tiles {
+		cb.Tiles[pos] = cm.Tiles[t]
+	}
+
+	//signatures are never changed once made, so they can be shared
+	cb.OpenPositions = make(map[util.Point[int]]*tile.EdgeSignature, len(b.OpenPositions))
+
+	for pos, sig := range b.OpenPositions {
+		cb.OpenPositions[pos] = sig
+	}
+
+	cb.openPositionsList = make([]util.Point[int], 0, cap(b.openPositionsList))
+
+	return cb, cm
+}
+
+// PlacedTiles
+// every tile on the board, ordered top to bottom then left to right
+func (b *Board) PlacedTiles() []*tile.Tile {
+	tiles := make([]*tile.Tile, 0, len(b.Tiles))
+
+	for _, t := range b.Tiles {
+		tiles = append(tiles, t)
+	}
+
+	sort.Slice(tiles, func(i, j int) bool {
+		return positionLess(tiles[i].Position, tiles[j].Position)
+	})
+
+	return tiles
+}
+
 func (b *Board) OpenPositionsList() []util.Point[int] {
 	b.openPositionsList = b.openPositionsList[:0]
 	for k := range b.OpenPositions {
 		b.openPositionsList = append(b.openPositionsList, k)
 	}
 
-	matrixSize := b.TileMatrix.Size()
-
 	//for determinism
 	sort.Slice(b.openPositionsList, func(i, j int) bool {
-		p1 := (b.openPositionsList[i].X + b.openPositionsList[i].Y*matrixSize)
-		p2 := (b.openPositionsList[j].X + b.openPositionsList[j].Y*matrixSize)
-		return p1 < p2
+		return positionLess(b.openPositionsList[i], b.openPositionsList[j])
 	})
 
 	return b.openPositionsList
 }
 
+// positionLess orders positions by row, then by column
+func positionLess(p1 util.Point[int], p2 util.Point[int]) bool {
+	if p1.Y != p2.Y {
+		return p1.Y < p2.Y
+	}
+
+	return p1.X < p2.X
+}
+
+func (b *Board) expandBounds(pos util.Point[int]) {
+	if len(b.Tiles) == 1 {
+		b.min, b.max = pos, pos
+		return
+	}
+
+	b.min, b.max = minPoint(b.min, pos), maxPoint(b.max, pos)
+}
+
+// recomputeBounds is only needed when a tile is removed, as it may have been on the edge
+func (b *Board) recomputeBounds() {
+	first := true
+
+	for pos := range b.Tiles {
+		if first {
+			b.min, b.max = pos, pos
+			first = false
+			continue
+		}
+
+		b.min, b.max = minPoint(b.min, pos), maxPoint(b.max, pos)
+	}
+
+	if first {
+		b.min, b.max = util.Point[int]{}, util.Point[int]{}
+	}
+}
+
+func minPoint(p1 util.Point[int], p2 util.Point[int]) util.Point[int] {
+	if p2.X < p1.X {
+		p1.X = p2.X
+	}
+
+	if p2.Y < p1.Y {
+		p1.Y = p2.Y
+	}
+
+	return p1
+}
+
+func maxPoint(p1 util.Point[int], p2 util.Point[int]) util.Point[int] {
+	if p2.X > p1.X {
+		p1.X = p2.X
+	}
+
+	if p2.Y > p1.Y {
+		p1.Y = p2.Y
+	}
+
+	return p1
+}
+
 func (b *Board) RemoveTileAt(pos util.Point[int]) {
-	t := b.TileMatrix.Get(pos.X, pos.Y)
+	b.removeTileAt(pos, true)
+}
+
+// PlaceHypotheticalTile
+// places the tile to see what it would do, the func returned takes it off again. the board's bounds are put back as they were,
+// rather than found again from every tile on the board
+func (b *Board) PlaceHypotheticalTile(pos util.Point[int], t *tile.Tile) (remove func()) {
+	boundsMin, boundsMax := b.min, b.max
+	b.PlaceTile(pos, t)
+
+	return func() {
+		b.removeTileAt(pos, false)
+		b.min, b.max = boundsMin, boundsMax
+	}
+}
+
+func (b *Board) removeTileAt(pos util.Point[int], recomputeBounds bool) {
+	t := b.Get(pos)
 
 	//no tile had yet been placed there
 	if t == nil {
@@ -64,8 +269,12 @@ func (b *Board) RemoveTileAt(pos util.Point[int]) {
 		t.Neighbours[dir] = nil
 	}
 
-	//remove the tile from the matrix
-	b.TileMatrix.Set(pos.X, pos.Y, nil)
+	//remove the tile from the board
+	delete(b.Tiles, pos)
+
+	if recomputeBounds && (pos.X == b.min.X || pos.Y == b.min.Y || pos.X == b.max.X || pos.Y == b.max.Y) {
+		b.recomputeBounds()
+	}
 
 	// special case, the first tile placement can be placed in unconnected areas
 	if b.PlacedTileCount > 1 {
@@ -85,13 +294,7 @@ func (b *Board) RemoveTileAt(pos util.Point[int]) {
 		// look though the adjacent tile's neighbours,
 		// if any are set, that open position can remain open
 		for _, p2 := range pn.OrthogonalNeighbours() {
-			matrixTile, err := b.TileMatrix.GetPt(p2)
-
-			if err != nil {
-				continue
-			}
-
-			if matrixTile != nil {
+			if b.Get(p2) != nil {
 				hasNeighbours = true
 				break
 			}
@@ -99,6 +302,12 @@ func (b *Board) RemoveTileAt(pos util.Point[int]) {
 
 		if !hasNeighbours {
 			delete(b.OpenPositions, pn)
+			continue
+		}
+
+		//the open position no longer has to match the removed tile's edge
+		if _, exists := b.OpenPositions[pn]; exists {
+			b.OpenPositions[pn] = b.createOpenPositonSignature(pn)
 		}
 	}
 
@@ -111,6 +320,19 @@ func (b *Board) RemoveTileAt(pos util.Point[int]) {
 	}
 }
 
+// SurroundingTiles gets the tiles which have been placed in the eight positions around pos
+func (b *Board) SurroundingTiles(pos util.Point[int]) []*tile.Tile {
+	tiles := make([]*tile.Tile, 0, 8)
+
+	for _, p := range pos.Neighbours() {
+		if t := b.Get(p); t != nil {
+			tiles = append(tiles, t)
+		}
+	}
+
+	return tiles
+}
+
 func (b *Board) linkNeighbours(t *tile.Tile) {
 	//link neighbours
 	for i := 0; i < 4; i++ {
@@ -119,19 +341,19 @@ func (b *Board) linkNeighbours(t *tile.Tile) {
 
 		pt := t.Position.EdgePos(dir)
 
-		if tl, err := b.TileMatrix.GetPt(pt); err == nil {
-			t.Neighbours[dir] = tl
-			if tl != nil {
-				tl.Neighbours[complimentDir] = t
-			}
+		tl := b.Get(pt)
+		t.Neighbours[dir] = tl
+		if tl != nil {
+			tl.Neighbours[complimentDir] = t
 		}
 	}
 }
 
 func (b *Board) PlaceTile(pos util.Point[int], t *tile.Tile) {
-	b.TileMatrix.Set(pos.X, pos.Y, t)
+	b.Tiles[pos] = t
 	b.PlacedTileCount++
 	t.Position = pos
+	b.expandBounds(pos)
 
 	b.linkNeighbours(t)
 
@@ -141,7 +363,7 @@ func (b *Board) PlaceTile(pos util.Point[int], t *tile.Tile) {
 			edgePos := pos.EdgePos(directions.Direction(d))
 
 			//don't add neighbours which are out of bounds
-			if !b.TileMatrix.IsInBounds(edgePos.X, edgePos.Y) {
+			if !b.IsInBounds(edgePos) {
 				continue
 			}
 
@@ -200,9 +422,9 @@ func (b *Board) createOpenPositonSignature(pos util.Point[int]) *tile.EdgeSignat
 	for i := 0; i < 4; i++ {
 		dir := directions.Direction(i)
 		complimentDirection := directions.Compliment[dir]
-		t, err := b.TileMatrix.GetPt(pos.EdgePos(dir))
+		t := b.Get(pos.EdgePos(dir))
 
-		if err != nil || t == nil {
+		if t == nil {
 			continue
 		}
 
@@ -0,0 +1,45 @@
+package engine_test
+
+import (
+	"beeb/carcassonne/engine/tile"
+	"beeb/carcassonne/util"
+	"testing"
+)
+
+func TestEngine_ScoreCloister(t *testing.T) {
+	gameData := loadGameData(t, "../data/standard_deck.yml")
+	e := newEngine(t, gameData, 16, 2, 1)
+
+	p0 := e.Players[0]
+	m := placeTestMeeple(p0, placeTestTile(e, "Cloister", 0, 5, 5), tile.Cloister)
+
+	//seven of the eight neighbours don't finish it
+	neighbours := []util.Point[int]{
+		{X: 4, Y: 4}, {X: 5, Y: 4}, {X: 6, Y: 4},
+		{X: 4, Y: 5}, {X: 6, Y: 5},
+		{X: 4, Y: 6}, {X: 5, Y: 6}, {X: 6, Y: 6},
+	}
+
+	for _, pos := range neighbours[:7] {
+		e.TilePlacedThisTurn = placeTestTile(e, "Cloister", 0, pos.X, pos.Y)
+
+		if scores := e.ScoreFinishedFeatures(); len(scores) != 0 {
+			t.Fatalf("cloister was scored with the tile at %v, before it had eight neighbours", pos)
+		}
+	}
+
+	if m.Feature == nil || p0.Score != 0 {
+		t.Fatalf("expected the meeple to stay on the unfinished cloister, with no points scored, got %d", p0.Score)
+	}
+
+	e.TilePlacedThisTurn = placeTestTile(e, "Cloister", 0, neighbours[7].X, neighbours[7].Y)
+	scores := e.ScoreFinishedFeatures()
+
+	if len(scores) != 1 || scores[0].Type != tile.Cloister || p0.Score != 9 {
+		t.Errorf("expected the finished cloister to score 9, got %+v and %d", scores, p0.Score)
+	}
+
+	if m.Feature != nil {
+		t.Error("expected the meeple to be returned")
+	}
+}
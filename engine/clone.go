@@ -0,0 +1,264 @@
+package engine
+
+import (
+	"beeb/carcassonne/engine/deck"
+	"beeb/carcassonne/engine/tile"
+	"beeb/carcassonne/util"
+	"math/rand"
+)
+
+// Clone
+// a deep copy of the engine, which can be played on without affecting the original, like for searching ahead.
+// the game data and reference tiles are shared, as nothing changes them during a game.
+// clones don't keep a game record, undo history or event subscribers, and players with an AI which isn't built-in share it with the original
+func (e *Engine) Clone() *Engine {
+	c := &Engine{}
+	*c = *e
+
+	c.randSource = e.randSource.clone()
+	c.Rand = rand.New(c.randSource)
+
+	gameBoard, cm := e.GameBoard.Clone()
+	c.GameBoard = gameBoard
+
+	numMeeples := 0
+	for _, p := range e.Players {
+		numMeeples += len(p.Meeples)
+	}
+
+	meeples := make(map[*Meeple]*Meeple, numMeeples)
+	players := make(map[*Player]*Player, len(e.Players))
+
+	c.Players = make([]*Player, len(e.Players))
+
+	for i, p := range e.Players {
+		c.Players[i] = clonePlayer(p, cm, meeples)
+		players[p] = c.Players[i]
+	}
+
+	//prisoners belong to the other players, so they're only cloned once every player's meeples are
+	for i, p := range e.Players {
+		c.Players[i].Prisoners = cloneMeepleRefs(p.Prisoners, meeples)
+
+		for _, m := range c.Players[i].Prisoners {
+			m.Captor = c.Players[i]
+		}
+	}
+
+	//the cloned features still hold the original meeples
+	for _, f := range cm.Features {
+		for i, m := range f.AttachedMeeples {
+			f.AttachedMeeples[i] = meeples[m.(*Meeple)]
+		}
+	}
+
+	c.TilePlacedThisTurn = cm.Tile(e.TilePlacedThisTurn)
+	c.lastRiverTile = cm.Tile(e.lastRiverTile)
+
+	c.DecidedActionThisTurn = cloneAction(e.DecidedActionThisTurn, meeples)
+	c.pendingAction = cloneAction(e.pendingAction, meeples)
+
+	if mp := e.DecidedMeeplePlacementThisTurn; mp != nil {
+		c.DecidedMeeplePlacementThisTurn = &MeeplePlacement{
+			ParentFeature:   cm.Feature(mp.ParentFeature),
+			SelectedMeeple:  cloneMeepleRef(mp.SelectedMeeple, meeples),
+			ReturnedMeeples: cloneMeepleRefs(mp.ReturnedMeeples, meeples),
+			ScoreGained:     mp.ScoreGained,
+			PortalPosition:  mp.PortalPosition,
+			PrincessMeeple:  cloneMeepleRef(mp.PrincessMeeple, meeples),
+			FairyMeeple:     cloneMeepleRef(mp.FairyMeeple, meeples),
+			RecalledAbbot:   cloneMeepleRef(mp.RecalledAbbot, meeples),
+			TowerPosition:   mp.TowerPosition,
+			CapturedMeeple:  cloneMeepleRef(mp.CapturedMeeple, meeples),
+		}
+	}
+
+	c.FairyMeeple = cloneMeepleRef(e.FairyMeeple, meeples)
+	c.dragonPath = clonePositions(e.dragonPath)
+
+	c.TowerHeights = make(map[util.Point[int]]int, len(e.TowerHeights))
+
+	for pos, height := range e.TowerHeights {
+		c.TowerHeights[pos] = height
+	}
+
+	c.FeaturesScoredThisTurn = cloneFeatureScores(e.FeaturesScoredThisTurn, cm, players)
+	c.EndGameScores = cloneFeatureScores(e.EndGameScores, cm, players)
+
+	if e.CurrentPossibleTilePlacements != nil {
+		c.CurrentPossibleTilePlacements = make([]Placement, len(e.CurrentPossibleTilePlacements))
+
+		for i, placement := range e.CurrentPossibleTilePlacements {
+			c.CurrentPossibleTilePlacements[i] = clonePlacement(placement, cm)
+		}
+	}
+
+	c.RiverDeck = cloneDeck(e.RiverDeck)
+	c.Deck = cloneDeck(e.Deck)
+	c.discardPile = copyTiles(e.discardPile)
+
+	if e.replayDraws != nil {
+		c.replayDraws = make([]string, len(e.replayDraws))
+		copy(c.replayDraws, e.replayDraws)
+	}
+
+	c.replayDragonMoves = clonePositions(e.replayDragonMoves)
+
+	c.TileFactory = &tile.TileFactory{}
+	c.TilePlacementManager = NewTilePlacementManager(c)
+	c.Record = nil
+	c.history = nil
+	c.UndoLimit = 0
+	c.redoTurns = nil
+	c.subscriptions = nil
+
+	return c
+}
+
+func clonePlayer(p *Player, cm *tile.CloneMap, meeples map[*Meeple]*Meeple) *Player {
+	cp := &Player{
+		Id:    p.Id,
+		Name:  p.Name,
+		Color: p.Color,
+		Score: p.Score,
+
+		TowerFloors: p.TowerFloors,
+	}
+
+	if p.hand != nil {
+		cp.hand = copyTiles(p.hand)
+	}
+
+	cp.Goods = make(map[tile.FeatureType]int, len(p.Goods))
+
+	for goodsType, count := range p.Goods {
+		cp.Goods[goodsType] = count
+	}
+
+	cp.Meeples = make([]*Meeple, len(p.Meeples))
+
+	for i, m := range p.Meeples {
+		cp.Meeples[i] = &Meeple{
+			Id:           m.Id,
+			Type:         m.Type,
+			Power:        m.Power,
+			ParentPlayer: cp,
+			Feature:      cm.Feature(m.Feature),
+		}
+
+		meeples[m] = cp.Meeples[i]
+	}
+
+	cp.AI = p.AI
+
+	if newAI, exists := PlayerAITypes[PlayerAITypeName(p.AI)]; exists {
+		cp.AI = newAI(cp)
+	}
+
+	return cp
+}
+
+// cloneAction
+// the action's reference tile and features are shared, only the meeples it points to belong to the engine
+func cloneAction(action *Action, meeples map[*Meeple]*Meeple) *Action {
+	if action == nil {
+		return nil
+	}
+
+	ca := *action
+	ca.PrincessMeeple = cloneMeepleRef(action.PrincessMeeple, meeples)
+	ca.FairyMeeple = cloneMeepleRef(action.FairyMeeple, meeples)
+	ca.RecalledAbbot = cloneMeepleRef(action.RecalledAbbot, meeples)
+	ca.CapturedMeeple = cloneMeepleRef(action.CapturedMeeple, meeples)
+
+	return &ca
+}
+
+func clonePositions(positions []util.Point[int]) []util.Point[int] {
+	if positions == nil {
+		return nil
+	}
+
+	cPositions := make([]util.Point[int], len(positions))
+	copy(cPositions, positions)
+
+	return cPositions
+}
+
+func cloneMeepleRef(m *Meeple, meeples map[*Meeple]*Meeple) *Meeple {
+	if m == nil {
+		return nil
+	}
+
+	return meeples[m]
+}
+
+func cloneMeepleRefs(ms []*Meeple, meeples map[*Meeple]*Meeple) []*Meeple {
+	if ms == nil {
+		return nil
+	}
+
+	cms := make([]*Meeple, len(ms))
+
+	for i, m := range ms {
+		cms[i] = cloneMeepleRef(m, meeples)
+	}
+
+	return cms
+}
+
+func cloneFeatureScores(scores []FeatureScore, cm *tile.CloneMap, players map[*Player]*Player) []FeatureScore {
+	if scores == nil {
+		return nil
+	}
+
+	cScores := make([]FeatureScore, len(scores))
+
+	for i, fs := range scores {
+		cScores[i] = fs
+		cScores[i].Feature = cm.Feature(fs.Feature)
+		cScores[i].Owners = make([]*Player, len(fs.Owners))
+
+		for j, owner := range fs.Owners {
+			cScores[i].Owners[j] = players[owner]
+		}
+
+		if fs.Bonuses != nil {
+			cScores[i].Bonuses = make(map[*Player]int, len(fs.Bonuses))
+
+			for p, bonus := range fs.Bonuses {
+				cScores[i].Bonuses[players[p]] = bonus
+			}
+		}
+	}
+
+	return cScores
+}
+
+func clonePlacement(placement Placement, cm *tile.CloneMap) Placement {
+	cPlacement := placement
+
+	if placement.ConnectedFeatures != nil {
+		cPlacement.ConnectedFeatures = make([]Connection, len(placement.ConnectedFeatures))
+
+		for i, connection := range placement.ConnectedFeatures {
+			connection.FeatureA = cm.Feature(connection.FeatureA)
+			connection.FeatureB = cm.Feature(connection.FeatureB)
+			cPlacement.ConnectedFeatures[i] = connection
+		}
+	}
+
+	return cPlacement
+}
+
+func cloneDeck(d *deck.Deck) *deck.Deck {
+	if d == nil {
+		return nil
+	}
+
+	cd := &deck.Deck{}
+	cd.Tiles = make([]*tile.ReferenceTileGroup, len(d.Tiles))
+	copy(cd.Tiles, d.Tiles)
+
+	return cd
+}
@@ -0,0 +1,79 @@
+package engine_test
+
+import (
+	"beeb/carcassonne/engine"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestEngine_Clone(t *testing.T) {
+	gameData := loadGameData(t, "../data/standard_deck.yml")
+
+	//an untouched game, to check the original isn't affected by playing the clone
+	reference := newEngine(t, gameData, 16, 4, 11)
+
+	for !reference.GameOver {
+		reference.Step()
+	}
+
+	referenceResult := gameResult(reference)
+
+	for _, steps := range []int{0, 9, 40, 150} {
+		t.Run(fmt.Sprint(steps, " steps"), func(t *testing.T) {
+			e := newEngine(t, gameData, 16, 4, 11)
+
+			for i := 0; i < steps; i++ {
+				e.Step()
+			}
+
+			c := e.Clone()
+
+			stateJson, _ := json.Marshal(engine.NewEngineState(e))
+			cloneStateJson, _ := json.Marshal(engine.NewEngineState(c))
+
+			if !bytes.Equal(stateJson, cloneStateJson) {
+				t.Fatal("clone state does not match the original")
+			}
+
+			//play the clone out first, anything it shares with the original will show up when the original is played
+			for !c.GameOver {
+				c.Step()
+			}
+
+			for !e.GameOver {
+				e.Step()
+			}
+
+			if !bytes.Equal(gameResult(e), referenceResult) {
+				t.Error("playing the clone changed the original game")
+			}
+
+			endStateJson, _ := json.Marshal(engine.NewEngineState(e))
+			cloneEndStateJson, _ := json.Marshal(engine.NewEngineState(c))
+
+			if !bytes.Equal(endStateJson, cloneEndStateJson) {
+				t.Error("the clone played out differently to the original")
+			}
+		})
+	}
+}
+
+// gameResult
+// the board and scores, which unlike player ids are the same for every game with the same seed
+func gameResult(e *engine.Engine) []byte {
+	state := engine.NewEngineState(e)
+	scores := make([]int, len(state.Players))
+
+	for i, p := range state.Players {
+		scores[i] = p.Score
+	}
+
+	result, _ := json.Marshal(struct {
+		Tiles  []engine.StateTile
+		Scores []int
+	}{state.Tiles, scores})
+
+	return result
+}
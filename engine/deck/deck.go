@@ -3,6 +3,7 @@ package deck
 import (
 	"beeb/carcassonne/engine/tile"
 	"errors"
+	"fmt"
 	"math/rand"
 )
 
@@ -32,12 +33,24 @@ func (d *Deck) Pop() (*tile.ReferenceTileGroup, error) {
 	return tile, nil
 }
 
+// Take removes the first tile in the deck with the given name, wherever it is in the deck
+func (d *Deck) Take(name string) (*tile.ReferenceTileGroup, error) {
+	for i, t := range d.Tiles {
+		if t.Name == name {
+			d.Tiles = append(d.Tiles[:i], d.Tiles[i+1:]...)
+			return t, nil
+		}
+	}
+
+	return nil, fmt.Errorf("deck has no %s tile", name)
+}
+
 func (d *Deck) Remaining() int {
 	return len(d.Tiles)
 }
 
-func (d *Deck) Shuffle() {
-	rand.Shuffle(len(d.Tiles), func(i, j int) {
+func (d *Deck) Shuffle(r *rand.Rand) {
+	r.Shuffle(len(d.Tiles), func(i, j int) {
 		d.Tiles[i], d.Tiles[j] = d.Tiles[j], d.Tiles[i]
 	})
 }
@@ -3,67 +3,40 @@ package deck
 import (
 	"beeb/carcassonne/data"
 	"beeb/carcassonne/engine/tile"
+	"math/rand"
 )
 
-func BuildRiverDeck(gameData *data.GameData) *Deck {
-	riverTiles := make([]*tile.ReferenceTileGroup, 0)
+// Build
+// a deck of every tile in the game data which include accepts, as many of each as the deck file lists, in the order of the tile names.
+// the deck isn't shuffled
+func Build(gameData *data.GameData, include func(rtg *tile.ReferenceTileGroup) bool) *Deck {
+	deck := &Deck{}
 
 	for _, tileName := range gameData.TileNames {
 
 		referenceTileGroup := gameData.ReferenceTileGroups[tileName]
 
-		if referenceTileGroup.IsRiverTerminus() {
+		if !include(referenceTileGroup) {
 			continue
 		}
 
-		if referenceTileGroup.IsRiverTile() {
-			riverTiles = append(riverTiles, referenceTileGroup)
-		}
-	}
-
-	deck := &Deck{}
-
-	for _, riverTileGroup := range riverTiles {
-		tileName := riverTileGroup.Name
 		tileCount := gameData.DeckInfo.Deck[tileName]
 		for i := 0; i < tileCount; i++ {
-			deck.Append(riverTileGroup)
+			deck.Append(referenceTileGroup)
 		}
 	}
 
-	terminus := gameData.ReferenceTileGroups["RiverTerminus"]
-
-	deck.Shuffle()
-
-	deck.Prepend(terminus)
-	deck.Append(terminus)
-
 	return deck
 }
 
-func BuildDeck(gameData *data.GameData) *Deck {
-	nonRiverTiles := make([]*tile.ReferenceTileGroup, 0)
-
-	for _, tileName := range gameData.TileNames {
-
-		referenceTileGroup := gameData.ReferenceTileGroups[tileName]
-
-		if !referenceTileGroup.IsRiverTile() {
-			nonRiverTiles = append(nonRiverTiles, referenceTileGroup)
-		}
-	}
-
-	deck := &Deck{}
-
-	for _, nonRiverTileGroup := range nonRiverTiles {
-		tileName := nonRiverTileGroup.Name
-		tileCount := gameData.DeckInfo.Deck[tileName]
-		for i := 0; i < tileCount; i++ {
-			deck.Append(nonRiverTileGroup)
-		}
-	}
+// BuildDeck
+// the main deck, every tile apart from the river's, which is built by the river variant the game is played with
+func BuildDeck(gameData *data.GameData, r *rand.Rand) *Deck {
+	deck := Build(gameData, func(rtg *tile.ReferenceTileGroup) bool {
+		return !rtg.IsRiverTile()
+	})
 
-	deck.Shuffle()
+	deck.Shuffle(r)
 
 	return deck
 }
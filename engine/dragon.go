@@ -0,0 +1,252 @@
+package engine
+
+import (
+	"beeb/carcassonne/engine/tile"
+	"beeb/carcassonne/util"
+)
+
+// DragonMover
+// an AI which decides where the dragon goes when it's their turn to move it.
+// the dragon is moved to the first of its moves for AIs which don't
+type DragonMover interface {
+	DetermineDragonMove(o *Observation, moves []util.Point[int]) util.Point[int]
+}
+
+// DragonMoves
+// the tiles next to the dragon it can move onto. it can't go back to a tile it's already been on this turn,
+// or onto the fairy's tile
+func (e *Engine) DragonMoves() []util.Point[int] {
+	if e.DragonPosition == nil {
+		return nil
+	}
+
+	moves := make([]util.Point[int], 0, 4)
+
+	for _, pos := range e.DragonPosition.OrthogonalNeighbours() {
+		if e.GameBoard.Get(pos) == nil {
+			continue
+		}
+
+		if e.FairyPosition != nil && *e.FairyPosition == pos {
+			continue
+		}
+
+		if containsPosition(e.dragonPath, pos) {
+			continue
+		}
+
+		moves = append(moves, pos)
+	}
+
+	return moves
+}
+
+// stepDragon
+// moves the dragon one tile when the tile placed this turn has a dragon on it, the players take turns moving it,
+// starting with the current player. false means the dragon has finished moving for this turn.
+// a dragon drawn before any volcano has been placed has nowhere to come from, so it doesn't move
+func (e *Engine) stepDragon() (bool, error) {
+	t := e.TilePlacedThisTurn
+
+	if t == nil || e.DragonPosition == nil || !t.Reference.HasFeatureType(tile.Dragon) {
+		return false, nil
+	}
+
+	if e.dragonPath == nil {
+		e.dragonPath = []util.Point[int]{*e.DragonPosition}
+	}
+
+	moved := len(e.dragonPath) - 1
+
+	if moved >= e.Rules.DragonMoves {
+		return false, nil
+	}
+
+	moves := e.DragonMoves()
+
+	if len(moves) < 1 {
+		return false, nil
+	}
+
+	player := e.Players[(e.CurrentPlayerIndex+moved)%len(e.Players)]
+
+	//a replay decides where the dragon goes, instead of the players
+	replayed := len(e.replayDragonMoves) > 0
+
+	var move util.Point[int]
+
+	if replayed {
+		move = e.replayDragonMoves[0]
+	} else {
+		move = e.determineDragonMove(player, moves)
+	}
+
+	if !containsPosition(moves, move) {
+		return false, &DecisionError{Player: player, Err: ErrIllegalDragonMove}
+	}
+
+	if replayed {
+		e.replayDragonMoves = e.replayDragonMoves[1:]
+	}
+
+	e.dragonPath = append(e.dragonPath, move)
+	e.Record.recordDragonMove(e.TurnCounter, move)
+	e.recordTurnDragonMove(move)
+
+	e.moveDragon(player, move)
+
+	return true, nil
+}
+
+func (e *Engine) determineDragonMove(player *Player, moves []util.Point[int]) util.Point[int] {
+	if mover, ok := player.AI.(DragonMover); ok {
+		return mover.DetermineDragonMove(e.Observe(player), moves)
+	}
+
+	return moves[0]
+}
+
+// moveDragon
+// puts the dragon on the tile at pos, where it eats every meeple, sending them back to their players
+func (e *Engine) moveDragon(player *Player, pos util.Point[int]) {
+	from := e.DragonPosition
+	e.DragonPosition = &pos
+
+	eaten := meeplesOnTile(e.GameBoard.Get(pos))
+
+	for _, m := range eaten {
+		m.Detach()
+	}
+
+	e.emit(DragonMovedEvent{
+		Turn:   e.TurnCounter,
+		Player: player,
+		From:   from,
+		To:     pos,
+		Eaten:  eaten,
+	})
+}
+
+// moveFairy
+// puts the fairy next to another of the current player's followers, instead of them placing a meeple
+func (e *Engine) moveFairy(mp *MeeplePlacement) error {
+	if err := e.validateFairy(mp); err != nil {
+		return err
+	}
+
+	m := mp.FairyMeeple
+	pos := m.Feature.ParentTile.Position
+
+	e.FairyMeeple = m
+	e.FairyPosition = &pos
+
+	e.emit(FairyMovedEvent{
+		Turn:   e.TurnCounter,
+		Player: m.ParentPlayer,
+		Meeple: m,
+	})
+
+	return nil
+}
+
+func (e *Engine) validateFairy(mp *MeeplePlacement) error {
+	player := e.CurrentPlayer()
+	m := mp.FairyMeeple
+
+	invalid := func(err error) error {
+		return &InvalidMeeplePlacementError{
+			Player:    player,
+			Placement: mp,
+			Err:       err,
+		}
+	}
+
+	if !e.Rules.Fairy {
+		return invalid(ErrNoFairy)
+	}
+
+	if m.ParentPlayer != player {
+		return invalid(ErrMeepleNotOwned)
+	}
+
+	if m.Type != Follower || m.Feature == nil || m == e.FairyMeeple {
+		return invalid(ErrFairyTarget)
+	}
+
+	return nil
+}
+
+// awardFairyTurnBonus
+// the owner of the follower the fairy's next to scores the turn bonus at the start of each of their turns
+func (e *Engine) awardFairyTurnBonus() {
+	m := e.FairyMeeple
+
+	if m == nil || m.ParentPlayer != e.CurrentPlayer() || e.Rules.FairyTurnBonus == 0 {
+		return
+	}
+
+	m.ParentPlayer.Score += e.Rules.FairyTurnBonus
+
+	e.emit(FairyScoredEvent{
+		Turn:   e.TurnCounter,
+		Player: m.ParentPlayer,
+		Meeple: m,
+		Score:  e.Rules.FairyTurnBonus,
+	})
+}
+
+// awardFairy
+// the owner of the follower the fairy's next to gets the fairy bonus when its feature is scored,
+// whether or not they own the feature. the fairy stays where she is, but she isn't with anyone after that
+func (e *Engine) awardFairy(featureChain *FeatureChain, bonuses map[*Player]int) map[*Player]int {
+	m := e.FairyMeeple
+
+	if m == nil {
+		return bonuses
+	}
+
+	for _, fm := range featureChain.meeples {
+		if fm != m {
+			continue
+		}
+
+		if bonuses == nil {
+			bonuses = make(map[*Player]int, 1)
+		}
+
+		bonuses[m.ParentPlayer] += e.Rules.FairyBonus
+		m.ParentPlayer.Score += e.Rules.FairyBonus
+		e.FairyMeeple = nil
+
+		break
+	}
+
+	return bonuses
+}
+
+// meeplesOnTile every meeple on the tile's features, in the order of the features
+func meeplesOnTile(t *tile.Tile) []*Meeple {
+	if t == nil {
+		return nil
+	}
+
+	meeples := make([]*Meeple, 0, 2)
+
+	for _, f := range t.Features {
+		for _, m := range f.AttachedMeeples {
+			meeples = append(meeples, m.(*Meeple))
+		}
+	}
+
+	return meeples
+}
+
+func containsPosition(positions []util.Point[int], pos util.Point[int]) bool {
+	for _, p := range positions {
+		if p == pos {
+			return true
+		}
+	}
+
+	return false
+}
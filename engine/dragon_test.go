@@ -0,0 +1,327 @@
+package engine_test
+
+import (
+	"beeb/carcassonne/data"
+	"beeb/carcassonne/engine"
+	"beeb/carcassonne/engine/tile"
+	"beeb/carcassonne/util"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestEngine_PrincessAndDragon(t *testing.T) {
+	gameData := loadGameData(t, "../data/princess_and_dragon_deck.yml")
+
+	rules, err := data.LoadRuleSet("../data/princess_and_dragon_rules.yml")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gameData.Rules = rules
+	e1 := newEngine(t, gameData, 24, 3, 4)
+
+	dragonMoves := 0
+	eaten := 0
+
+	e1.Subscribe(engine.EventSubscriberFunc(func(e *engine.Engine, event engine.Event) {
+		dm, ok := event.(engine.DragonMovedEvent)
+
+		if !ok {
+			return
+		}
+
+		if dm.From != nil {
+			dragonMoves++
+		}
+
+		for _, m := range dm.Eaten {
+			if m.Feature != nil {
+				t.Errorf("turn %d: meeple eaten by the dragon is still on the board", dm.Turn)
+			}
+		}
+
+		eaten += len(dm.Eaten)
+
+		if e.FairyPosition != nil && *e.FairyPosition == dm.To {
+			t.Errorf("turn %d: the dragon moved onto the fairy's tile", dm.Turn)
+		}
+	}))
+
+	for !e1.GameOver {
+		if err := e1.Step(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if e1.DragonPosition == nil || dragonMoves == 0 {
+		t.Fatal("expected the dragon to come out of a volcano and move")
+	}
+
+	//the dragon goes the same way in a replay, eating the same meeples
+	e2, err := engine.Replay(gameData, e1.Record)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if *e2.DragonPosition != *e1.DragonPosition {
+		t.Errorf("dragon ended up at %v in the replay, expected %v", *e2.DragonPosition, *e1.DragonPosition)
+	}
+
+	tiles1, _ := json.Marshal(engine.NewEngineState(e1).Tiles)
+	tiles2, _ := json.Marshal(engine.NewEngineState(e2).Tiles)
+
+	if !bytes.Equal(tiles1, tiles2) {
+		t.Error("replayed board does not match the recorded game")
+	}
+
+	for i, p := range e1.Players {
+		if e2.Players[i].Score != p.Score {
+			t.Errorf("player %d scored %d in the replay, expected %d", i, e2.Players[i].Score, p.Score)
+		}
+	}
+}
+
+// strayDragonAI places tiles like the basic AI, but sends the dragon somewhere it can't go
+type strayDragonAI struct {
+	engine.BasicPlayerAI
+}
+
+func (ai *strayDragonAI) DetermineDragonMove(o *engine.Observation, moves []util.Point[int]) util.Point[int] {
+	return util.Point[int]{X: -100, Y: -100}
+}
+
+func TestEngine_StepUntilDecisionError(t *testing.T) {
+	gameData := loadGameData(t, "../data/princess_and_dragon_deck.yml")
+
+	rules, err := data.LoadRuleSet("../data/princess_and_dragon_rules.yml")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gameData.Rules = rules
+	e := newEngine(t, gameData, 24, 3, 4)
+
+	for _, p := range e.Players {
+		p.AI = &strayDragonAI{}
+	}
+
+	for err == nil && !e.GameOver {
+		err = e.Step()
+	}
+
+	if !errors.Is(err, engine.ErrIllegalDragonMove) {
+		t.Fatalf("Step() error = %v, want %v", err, engine.ErrIllegalDragonMove)
+	}
+
+	//the engine is stuck until the dragon's moved, so it mustn't keep trying
+	var decisionErr *engine.DecisionError
+
+	if err := e.StepUntilDecision(); !errors.As(err, &decisionErr) || !errors.Is(err, engine.ErrIllegalDragonMove) {
+		t.Errorf("StepUntilDecision() error = %v, want a decision error for %v", err, engine.ErrIllegalDragonMove)
+	}
+}
+
+func newPrincessAndDragonEngine(t *testing.T, numPlayers int) *engine.Engine {
+	gameData := loadGameData(t, "../data/princess_and_dragon_deck.yml")
+
+	rules, err := data.LoadRuleSet("../data/princess_and_dragon_rules.yml")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gameData.Rules = rules
+
+	return newEngine(t, gameData, 16, numPlayers, 1)
+}
+
+// decideMeeple places the tile as the current player's tile this turn, and has them decide on the meeple placement
+func decideMeeple(e *engine.Engine, t *tile.Tile, mp *engine.MeeplePlacement) error {
+	e.TilePlacedThisTurn = t
+	e.DecidedMeeplePlacementThisTurn = mp
+
+	return e.PlaceMeepleOnFeature()
+}
+
+func TestEngine_Princess(t *testing.T) {
+	e := newPrincessAndDragonEngine(t, 2)
+	e.CurrentPlayerIndex = 0
+
+	p1 := e.Players[1]
+
+	//the second player's follower is in the castle the princess joins, another is in a castle of its own
+	m := placeTestMeeple(p1, placeTestTile(e, "CastleEndCap", 1, 4, 5), tile.Castle)
+	other := placeTestMeeple(p1, placeTestTile(e, "CastleEndCap", 1, 4, 9), tile.Castle)
+
+	princess := placeTestTile(e, "CastleLongPrincess", 0, 5, 5)
+
+	if err := decideMeeple(e, princess, &engine.MeeplePlacement{PrincessMeeple: other}); !errors.Is(err, engine.ErrPrincessTarget) {
+		t.Errorf("princess sending home a follower in another castle, error = %v, want %v", err, engine.ErrPrincessTarget)
+	}
+
+	if err := decideMeeple(e, placeTestTile(e, "CastleEndCap", 3, 6, 9), &engine.MeeplePlacement{PrincessMeeple: other}); !errors.Is(err, engine.ErrNoPrincess) {
+		t.Errorf("sending a follower home without a princess, error = %v, want %v", err, engine.ErrNoPrincess)
+	}
+
+	if err := decideMeeple(e, princess, &engine.MeeplePlacement{PrincessMeeple: m}); err != nil {
+		t.Fatal(err)
+	}
+
+	//the follower goes home without scoring anything
+	if m.Feature != nil || other.Feature == nil || p1.Score != 0 {
+		t.Errorf("expected only the follower in the princess's castle to be sent home, with no points scored, got %d", p1.Score)
+	}
+}
+
+func TestEngine_Portal(t *testing.T) {
+	e := newPrincessAndDragonEngine(t, 2)
+	e.CurrentPlayerIndex = 0
+
+	p0 := e.Players[0]
+
+	//an unfinished castle, and a finished one, away from the portal
+	open := placeTestTile(e, "CastleEndCap", 0, 8, 5)
+	closed := placeTestTile(e, "CastleEndCap", 0, 8, 2)
+	placeTestTile(e, "CastleEndCap", 2, 8, 1)
+
+	portal := placeTestTile(e, "RoadCurvePortal", 0, 5, 5)
+
+	m := p0.GetAvailableMeeple()
+	mp := &engine.MeeplePlacement{
+		SelectedMeeple: m,
+		ParentFeature:  featureOfType(closed, tile.Castle).ParentFeature,
+		PortalPosition: &closed.Position,
+	}
+
+	if err := decideMeeple(e, portal, mp); !errors.Is(err, engine.ErrFeatureComplete) {
+		t.Errorf("through the portal to a finished castle, error = %v, want %v", err, engine.ErrFeatureComplete)
+	}
+
+	mp.ParentFeature = featureOfType(open, tile.Castle).ParentFeature
+	mp.PortalPosition = &open.Position
+
+	if err := decideMeeple(e, placeTestTile(e, "RoadCurve", 0, 5, 9), mp); !errors.Is(err, engine.ErrNoPortal) {
+		t.Errorf("through a portal from a tile without one, error = %v, want %v", err, engine.ErrNoPortal)
+	}
+
+	if err := decideMeeple(e, portal, mp); err != nil {
+		t.Fatal(err)
+	}
+
+	if m.Feature == nil || m.Feature.ParentTile != open {
+		t.Error("expected the meeple to go through the portal to the unfinished castle")
+	}
+}
+
+func TestEngine_DragonOnTile(t *testing.T) {
+	e := newPrincessAndDragonEngine(t, 2)
+	e.CurrentPlayerIndex = 0
+
+	p0 := e.Players[0]
+
+	//the dragon comes out of the volcano as it's placed
+	volcano := placeTestTile(e, "Volcano", 0, 5, 5)
+
+	mp := &engine.MeeplePlacement{
+		SelectedMeeple: p0.GetAvailableMeeple(),
+		ParentFeature:  featureOfType(volcano, tile.Farm).ParentFeature,
+	}
+
+	if _, err := e.ValidateMeeplePlacement(volcano, mp); !errors.Is(err, engine.ErrDragonOnTile) {
+		t.Errorf("meeple on a volcano, error = %v, want %v", err, engine.ErrDragonOnTile)
+	}
+
+	//nor can a meeple go through a portal to the dragon
+	e.DragonPosition = &volcano.Position
+	mp.PortalPosition = &volcano.Position
+
+	if _, err := e.ValidateMeeplePlacement(placeTestTile(e, "CastleEndCapPortal", 0, 8, 8), mp); !errors.Is(err, engine.ErrDragonOnTile) {
+		t.Errorf("meeple through a portal to the dragon's tile, error = %v, want %v", err, engine.ErrDragonOnTile)
+	}
+}
+
+func TestEngine_Fairy(t *testing.T) {
+	e := newPrincessAndDragonEngine(t, 2)
+	e.CurrentPlayerIndex = 0
+
+	p0 := e.Players[0]
+
+	top := placeTestTile(e, "CastleEndCap", 2, 5, 4)
+	m := placeTestMeeple(p0, top, tile.Castle)
+
+	if err := decideMeeple(e, placeTestTile(e, "RoadStraight", 0, 9, 9), &engine.MeeplePlacement{FairyMeeple: m}); err != nil {
+		t.Fatal(err)
+	}
+
+	if e.FairyMeeple != m || *e.FairyPosition != top.Position {
+		t.Fatal("expected the fairy to move next to the follower")
+	}
+
+	//the turn bonus only comes at the start of the follower's owner's turn
+	for turn, expected := range []int{0, 1} {
+		if err := e.GoToNextTurn(); err != nil {
+			t.Fatal(err)
+		}
+
+		if p0.Score != expected {
+			t.Errorf("turn %d: expected the fairy's turn bonus to have given %d, got %d", turn, expected, p0.Score)
+		}
+	}
+
+	//finishing the castle scores it, and the fairy bonus on top
+	e.TilePlacedThisTurn = placeTestTile(e, "CastleEndCap", 0, 5, 5)
+	scores := e.ScoreFinishedFeatures()
+
+	if len(scores) != 1 || scores[0].Score != 4 || scores[0].Bonuses[p0] != 3 {
+		t.Fatalf("expected the castle to score 4 with a fairy bonus of 3, got %+v", scores)
+	}
+
+	if p0.Score != 1+4+3 || e.FairyMeeple != nil {
+		t.Errorf("expected the fairy to leave after scoring 8 in all, got %d", p0.Score)
+	}
+}
+
+func TestEngine_OneMeepleAction(t *testing.T) {
+	e := newPrincessAndDragonEngine(t, 2)
+	e.CurrentPlayerIndex = 0
+
+	p0 := e.Players[0]
+
+	castle := placeTestTile(e, "CastleEndCap", 0, 5, 5)
+	m := placeTestMeeple(p0, castle, tile.Castle)
+	placed := placeTestTile(e, "CastleEndCap", 0, 9, 9)
+	pos := castle.Position
+
+	tests := []struct {
+		name string
+		mp   *engine.MeeplePlacement
+	}{
+		{"meeple and fairy", &engine.MeeplePlacement{
+			SelectedMeeple: p0.GetAvailableMeeple(),
+			ParentFeature:  featureOfType(placed, tile.Castle).ParentFeature,
+			FairyMeeple:    m,
+		}},
+		{"princess and fairy", &engine.MeeplePlacement{PrincessMeeple: m, FairyMeeple: m}},
+		{"meeple and tower", &engine.MeeplePlacement{SelectedMeeple: p0.GetAvailableMeeple(), TowerPosition: &pos}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := decideMeeple(e, placed, tt.mp)
+
+			var placementErr *engine.InvalidMeeplePlacementError
+			if !errors.As(err, &placementErr) || !errors.Is(err, engine.ErrOneMeepleAction) {
+				t.Errorf("PlaceMeepleOnFeature() error = %v, want %v", err, engine.ErrOneMeepleAction)
+			}
+
+			if e.FairyMeeple != nil || m.Feature == nil {
+				t.Error("expected nothing to be done when more than one thing was decided on")
+			}
+		})
+	}
+}
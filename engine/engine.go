@@ -7,39 +7,45 @@ import (
 	"beeb/carcassonne/engine/tile"
 	"beeb/carcassonne/engine/turnStage"
 	"beeb/carcassonne/util"
-	"beeb/carcassonne/util/directions"
 	"errors"
 	"fmt"
-	"image/color"
-	"sort"
-
-	"golang.org/x/exp/shiny/materialdesign/colornames"
+	"math/rand"
 )
 
-var PLAYER_COLOR_LIST = [...]color.RGBA{
-	colornames.White,
-	colornames.Red500,
-	colornames.Blue500,
-	colornames.Green500,
-	colornames.Black,
-}
-
 type Engine struct {
 	Deterministic                  bool
+	Seed                           int64
+	Rand                           *rand.Rand
 	BoardSize                      int
 	GameOver                       bool
 	GameBoard                      *board.Board
 	GameData                       *data.GameData
+	Rules                          *data.RuleSet
+	Setup                          *data.GameSetup
 	Players                        []*Player
 	CurrentPlayerIndex             int
 	TilePlacedThisTurn             *tile.Tile
+	DecidedActionThisTurn          *Action
 	DecidedMeeplePlacementThisTurn *MeeplePlacement
+	FeaturesScoredThisTurn         []FeatureScore
+	MeeplePlacementError           error
 	HeldRefTileGroup               *tile.ReferenceTileGroup
 	CurrentPossibleTilePlacements  []Placement
 
 	TurnCounter int
 	TurnStage   turnStage.TurnStage
 
+	EndGameScores []FeatureScore
+
+	//the dragon is on the board once the first volcano is placed, the fairy once she's first moved.
+	//she stays where she is when her follower leaves the board, until she's moved again
+	DragonPosition *util.Point[int]
+	FairyPosition  *util.Point[int]
+	FairyMeeple    *Meeple
+
+	//the towers on the board by position, and how many floors have been built on each
+	TowerHeights map[util.Point[int]]int
+
 	RiverDeck *deck.Deck
 	Deck      *deck.Deck
 
@@ -47,56 +53,139 @@ type Engine struct {
 
 	TilePlacementManager *TilePlacementManager
 
+	Record *GameRecord
+
+	//how many turns can be undone, there's no undo history unless it's set,
+	//as saving it every turn slows down engines which don't need it, like those an AI searches ahead with
+	UndoLimit int
+
+	pendingAction     *Action
+	replayDraws       []string
+	replayDragonMoves []util.Point[int]
+	randSource        *countedSource
+
+	history   []turnHistory
+	redoTurns []redoTurn
+
+	subscriptions []*subscription
+
 	isFirstRiverTurn bool
 	lastRiverTurn    int
 	lastRiverTile    *tile.Tile
+
+	//builderTurn is set for the extra turn a builder gives, which can't give another.
+	//builderTriggered is set when the tile placed this turn extended the player's builder
+	builderTurn      bool
+	builderTriggered bool
+
+	//the tiles the dragon has been on while it's moving this turn, starting where it was
+	dragonPath []util.Point[int]
+
+	//the tiles removed from the game because they couldn't be placed, in the order they were removed
+	discardPile []*tile.ReferenceTileGroup
+}
+
+// NewEngine
+// every engine has its own random source, so games with the same seed play out the same way.
+// pass board.Unbounded as the board size for a board without edges
+func NewEngine(gameData *data.GameData, boardSize int, numPlayers int, seed int64) (*Engine, error) {
+	if numPlayers < 1 {
+		return nil, fmt.Errorf("%w: %d, there must be at least 1", ErrPlayerCount, numPlayers)
+	}
+
+	return NewEngineFromSetup(gameData, boardSize, data.DefaultGameSetup(numPlayers), seed)
 }
 
-func NewEngine(gameData *data.GameData, boardSize int, numPlayers int) *Engine {
-	if numPlayers > len(PLAYER_COLOR_LIST) {
-		panic(fmt.Sprint("too many players for the colors implemented, max ", len(PLAYER_COLOR_LIST)))
+// NewEngineFromSetup
+// like NewEngine, with the players set up as the game setup says
+func NewEngineFromSetup(gameData *data.GameData, boardSize int, setup *data.GameSetup, seed int64) (*Engine, error) {
+	if len(setup.Players) < 1 {
+		return nil, fmt.Errorf("%w: %d, there must be at least 1", ErrPlayerCount, len(setup.Players))
+	}
+
+	if err := validateSetup(setup); err != nil {
+		return nil, err
 	}
 
 	engine := &Engine{}
 
+	engine.Seed = seed
 	engine.BoardSize = boardSize
 	engine.GameData = gameData
-	engine.Players = make([]*Player, numPlayers)
+	engine.Rules = gameData.Rules
+
+	if engine.Rules == nil {
+		engine.Rules = data.DefaultRuleSet()
+	}
+
+	engine.Setup = setup
 	engine.TileFactory = &tile.TileFactory{}
 	engine.TilePlacementManager = NewTilePlacementManager(engine)
 
 	engine.InitGame()
 
-	return engine
+	return engine, nil
 }
 
+// InitGame
+// starts a new game, played by the engine's rules, with the players in its setup
 func (e *Engine) InitGame() {
-	for i := 0; i < len(e.Players); i++ {
-		playerName := fmt.Sprint("Player ", i)
-		e.Players[i] = NewPlayer(playerName, PLAYER_COLOR_LIST[i])
+	e.Players = make([]*Player, len(e.Setup.Players))
+	colors := setupColors(e.Setup)
+
+	for i, ps := range e.Setup.Players {
+		e.Players[i] = e.newSetupPlayer(i, ps, colors[i])
 	}
 
+	//restarting the game restarts the random source, so it plays out the same way again
+	e.randSource = newCountedSource(e.Seed, 0)
+	e.Rand = rand.New(e.randSource)
+
 	e.GameBoard = board.NewBoard(e.BoardSize)
-	e.RiverDeck = deck.BuildRiverDeck(e.GameData)
-	e.Deck = deck.BuildDeck(e.GameData)
+	e.RiverDeck = e.River().BuildDeck(e.GameData, e.Rand)
+	e.Deck = deck.BuildDeck(e.GameData, e.Rand)
 	e.GameOver = false
+	e.EndGameScores = nil
 	e.TurnCounter = 0
 	e.TilePlacedThisTurn = nil
+	e.DecidedActionThisTurn = nil
 	e.DecidedMeeplePlacementThisTurn = nil
+	e.FeaturesScoredThisTurn = nil
+	e.MeeplePlacementError = nil
 	e.HeldRefTileGroup = nil
 	e.CurrentPossibleTilePlacements = nil
 	e.CurrentPlayerIndex = 0
+	e.pendingAction = nil
+	e.replayDraws = nil
+	e.replayDragonMoves = nil
+	e.history = nil
+	e.redoTurns = nil
+	e.Record = NewGameRecord(e)
 	e.TurnStage = turnStage.Draw
 
 	e.isFirstRiverTurn = true
 	e.lastRiverTurn = 1
 	e.lastRiverTile = nil
+
+	e.builderTurn = false
+	e.builderTriggered = false
+
+	e.DragonPosition = nil
+	e.FairyPosition = nil
+	e.FairyMeeple = nil
+	e.dragonPath = nil
+
+	e.TowerHeights = make(map[util.Point[int]]int)
+	e.discardPile = nil
 }
 
-func (e *Engine) Step() {
+// Step
+// moves the game on to the next stage of the turn. an error means the player couldn't decide on their turn,
+// which is a *DecisionError, the game is left as it was so the step can be tried again
+func (e *Engine) Step() error {
 
 	if e.GameOver {
-		return
+		return nil
 	}
 
 	player := e.CurrentPlayer()
@@ -105,36 +194,56 @@ func (e *Engine) Step() {
 	case turnStage.Draw:
 
 		e.TilePlacedThisTurn = nil
+		e.DecidedActionThisTurn = nil
 		e.DecidedMeeplePlacementThisTurn = nil
+		e.FeaturesScoredThisTurn = nil
+		e.MeeplePlacementError = nil
 		e.HeldRefTileGroup = nil
 		e.CurrentPossibleTilePlacements = nil
 
+		e.beginTurnHistory()
+
+		if e.playsHands() {
+			e.drawHand(player)
+			return nil
+		}
+
 		//retry getting possible tiles a few times if we don't have a place to put one
-		for i := 0; i < 3; i++ {
+		for i := 0; i < e.Rules.DrawAttempts; i++ {
 
 			rtg, tileTakeErr := e.TakeNextTile()
 
 			if tileTakeErr != nil {
 				//attempted to take a tile from an empty deck, so end the game
 				e.EndGame()
-				return
+				return nil
 			}
 
 			e.HeldRefTileGroup = rtg
+			e.updatePossibleTilePlacements()
 
-			e.CurrentPossibleTilePlacements = e.TilePlacementManager.PossibleTilePlacements(rtg)
+			//a river tile can't wait in the main deck for a better spot, the river has moved on by then,
+			//so one with nowhere to go is removed from the game
+			if len(e.CurrentPossibleTilePlacements) < 1 && rtg.IsRiverTile() {
+				e.HeldRefTileGroup = nil
+				e.discardTile(player, rtg)
 
-			if e.GameBoard.PlacedTileCount > 0 && (e.RiverDeck.Remaining() > 0 || rtg.IsRiverTile()) {
-				e.CurrentPossibleTilePlacements, _ = e.restictRiverPlacement()
+				continue
 			}
 
 			//this clause shuffles a tile back in when it is not playable
-
-			// this should never happen to the river
 			if len(e.CurrentPossibleTilePlacements) < 1 {
 				//replace tile
 				e.Deck.Append(e.HeldRefTileGroup)
-				e.Deck.Shuffle()
+				e.Deck.Shuffle(e.Rand)
+
+				e.emit(TileDiscardedEvent{
+					Turn:       e.TurnCounter,
+					Player:     player,
+					Tile:       e.HeldRefTileGroup,
+					Reshuffled: true,
+				})
+
 				continue
 			}
 
@@ -142,34 +251,101 @@ func (e *Engine) Step() {
 		}
 
 		if len(e.CurrentPossibleTilePlacements) < 1 {
-			//"nowhere to place tile, tried 3 times, just remove tile completely" (take and do not place)
-			_, _ = e.TakeNextTile()
-			return
+			//"nowhere to place tile, tried a few times, just remove tile completely" (take and do not place)
+			if rtg, err := e.TakeNextTile(); err == nil {
+				e.discardTile(player, rtg)
+			}
+
+			return nil
 		}
 
 		e.TurnStage++
 
 	case turnStage.PlaceTile:
 
-		selectedTilePlacement, meeplePlacement := player.DeterminePlacement(e, e.CurrentPossibleTilePlacements)
-		e.DecidedMeeplePlacementThisTurn = meeplePlacement
+		//an action applied to the engine takes the place of the player's decision
+		action := e.pendingAction
+		e.pendingAction = nil
+
+		var decidedMeeplePlacement *MeeplePlacement
 
-		if selectedTilePlacement == nil {
-			panic("No Placement Determined By Player")
+		if action == nil {
+			selectedTilePlacement, meeplePlacement := player.DeterminePlacement(e, e.CurrentPossibleTilePlacements)
+
+			//the turn stays where it is, so the player can be asked again
+			if selectedTilePlacement == nil {
+				return &DecisionError{Player: player, Err: ErrNoTilePlacement}
+			}
+
+			decidedAction := e.gameAction(NewAction(*selectedTilePlacement, meeplePlacement))
+
+			if !e.isLegalPlacement(decidedAction) {
+				return &DecisionError{Player: player, Err: ErrIllegalTilePlacement}
+			}
+
+			//the AI's own meeple is placed, so one which isn't theirs, or which they don't have, is rejected
+			decidedMeeplePlacement = e.meeplePlacementForAction(decidedAction)
+
+			if decidedAction.PlacesMeeple() {
+				decidedMeeplePlacement.SelectedMeeple = e.gameMeeple(meeplePlacement.SelectedMeeple)
+			}
+
+			if err := e.validateActionMeeple(decidedAction, decidedMeeplePlacement); err != nil {
+				return &DecisionError{Player: player, Err: err}
+			}
+
+			action = &decidedAction
+		} else {
+			decidedMeeplePlacement = e.meeplePlacementForAction(*action)
+		}
+
+		e.DecidedActionThisTurn = action
+		e.Record.recordAction(e.TurnCounter, e.CurrentPlayerIndex, e.newStateAction(*action))
+		e.recordTurnAction(*action)
+		e.DecidedMeeplePlacementThisTurn = decidedMeeplePlacement
+
+		//the held tile is the last of the river, or the player's picked one from their hand
+		if e.HeldRefTileGroup == nil {
+			player.removeFromHand(e.GameData.ReferenceTileGroups[action.ReferenceTile.Name])
 		}
 
-		e.TilePlacedThisTurn = e.PlaceTile(*selectedTilePlacement)
+		e.TilePlacedThisTurn = e.PlaceTile(action.Placement())
+		e.builderTriggered = !e.builderTurn && e.extendsBuilder(player, e.TilePlacedThisTurn)
+
+		e.emit(TilePlacedEvent{
+			Turn:   e.TurnCounter,
+			Player: player,
+			Tile:   e.TilePlacedThisTurn,
+			Action: *action,
+		})
+
+		//the dragon comes straight out of a volcano
+		if e.TilePlacedThisTurn.Reference.HasFeatureType(tile.Volcano) {
+			e.moveDragon(player, e.TilePlacedThisTurn.Position)
+		}
 
 		e.CurrentPossibleTilePlacements = nil
 		e.HeldRefTileGroup = nil
 		e.TurnStage++
 
 	case turnStage.PlaceMeeple:
-		e.PlaceMeepleOnFeature()
+		e.MeeplePlacementError = e.PlaceMeepleOnFeature()
 		e.TurnStage++
 
+	case turnStage.MoveDragon:
+		moved, err := e.stepDragon()
+
+		if err != nil {
+			return err
+		}
+
+		if !moved {
+			e.dragonPath = nil
+			e.TurnStage++
+		}
+
 	case turnStage.Score:
-		e.ScoreFinishedFeature()
+		e.FeaturesScoredThisTurn = e.ScoreFinishedFeatures()
 		e.TurnStage++
 
 	case turnStage.Pass:
@@ -177,71 +353,173 @@ func (e *Engine) Step() {
 
 		if err != nil {
 			e.EndGame()
+		} else {
+			e.emit(TurnPassedEvent{
+				Turn:           e.TurnCounter,
+				Player:         e.CurrentPlayer(),
+				PreviousPlayer: player,
+			})
 		}
 
 		e.TurnStage = turnStage.Draw
 	}
+
+	return nil
 }
 
-func (e *Engine) ScoreFinishedFeature() {
+// PlaceMeepleOnFeature
+// places the meeple the player decided on, if the placement is legal, or sends the princess, moves the fairy, recalls the abbot
+// or builds a tower floor instead.
+// an illegal placement is rejected and no meeple is placed this turn
+func (e *Engine) PlaceMeepleOnFeature() error {
+
+	t := e.TilePlacedThisTurn
 	mp := e.DecidedMeeplePlacementThisTurn
 
+	if t == nil {
+		return nil
+	}
+
 	if mp == nil {
-		return
+		return nil
+	}
+
+	if err := e.validateOneMeepleAction(mp); err != nil {
+		return err
 	}
 
-	playerMeepleCountMap := make(map[*Player]int)
+	if mp.PrincessMeeple != nil {
+		return e.sendPrincess(t, mp)
+	}
 
-	for _, m := range mp.ReturnedMeeples {
+	if mp.FairyMeeple != nil {
+		return e.moveFairy(mp)
+	}
 
-		//fast-remove meeple from feature list
-		for i, am := range m.Feature.AttachedMeeples {
-			if am == m {
-				l := len(m.Feature.AttachedMeeples) - 1
-				m.Feature.AttachedMeeples[i] = m.Feature.AttachedMeeples[l]
-				m.Feature.AttachedMeeples = m.Feature.AttachedMeeples[:l]
-			}
-		}
+	if mp.RecalledAbbot != nil {
+		return e.recallAbbot(mp)
+	}
+
+	if mp.TowerPosition != nil || mp.CapturedMeeple != nil {
+		return e.buildTower(t, mp)
+	}
+
+	if mp.SelectedMeeple == nil {
+		return nil
+	}
+
+	newTileFeature, err := e.ValidateMeeplePlacement(t, mp)
+
+	if err != nil {
+		return err
+	}
+
+	mp.SelectedMeeple.Feature = newTileFeature
+	newTileFeature.AttachedMeeples = append(newTileFeature.AttachedMeeples, mp.SelectedMeeple)
+
+	e.emit(MeeplePlacedEvent{
+		Turn:    e.TurnCounter,
+		Player:  mp.SelectedMeeple.ParentPlayer,
+		Meeple:  mp.SelectedMeeple,
+		Feature: newTileFeature,
+	})
 
-		//setting the meeples feature to nil returns it to the pool
-		m.Feature = nil
+	return nil
+}
 
-		playerMeepleCountMap[m.ParentPlayer]++
+// validateMeepleDecision
+// checks whichever of placing a meeple, sending the princess, moving the fairy, recalling the abbot or building a tower the player decided on
+func (e *Engine) validateMeepleDecision(t *tile.Tile, mp *MeeplePlacement) error {
+	if err := e.validateOneMeepleAction(mp); err != nil {
+		return err
 	}
 
-	mostPlayersOnFeature := 0
-	for _, c := range playerMeepleCountMap {
-		if c > mostPlayersOnFeature {
-			mostPlayersOnFeature = c
+	var err error
+
+	switch {
+	case mp.PrincessMeeple != nil:
+		_, err = e.validatePrincess(t, mp)
+	case mp.FairyMeeple != nil:
+		err = e.validateFairy(mp)
+	case mp.RecalledAbbot != nil:
+		err = e.validateRecallAbbot(mp)
+	case mp.TowerPosition != nil || mp.CapturedMeeple != nil:
+		err = e.validateTower(t, mp)
+	case mp.SelectedMeeple != nil:
+		_, err = e.ValidateMeeplePlacement(t, mp)
+	}
+
+	return err
+}
+
+func (e *Engine) validateOneMeepleAction(mp *MeeplePlacement) error {
+	decisions := 0
+
+	for _, m := range []*Meeple{mp.SelectedMeeple, mp.PrincessMeeple, mp.FairyMeeple, mp.RecalledAbbot} {
+		if m != nil {
+			decisions++
 		}
 	}
 
-	if mp.ScoreGained > 0 {
-		for p, c := range playerMeepleCountMap {
-			if c == mostPlayersOnFeature {
-				p.Score += mp.ScoreGained
-			}
+	if mp.TowerPosition != nil {
+		decisions++
+	}
+
+	if decisions > 1 {
+		return &InvalidMeeplePlacementError{
+			Player:    e.CurrentPlayer(),
+			Placement: mp,
+			Err:       ErrOneMeepleAction,
 		}
 	}
 
+	return nil
 }
 
-// the feature selected by the player is only theoretical, the actual tile will have a different feature entirely
-func (e *Engine) PlaceMeepleOnFeature() {
+// ValidateMeeplePlacement
+// checks the meeple placement against the rules, and finds the feature on the tile it would be placed on.
+// the feature selected by the player is only theoretical, the actual tile will have a different feature entirely.
+// t is the tile placed this turn, the meeple goes on the tile at the placement's portal position instead when it has one
+func (e *Engine) ValidateMeeplePlacement(t *tile.Tile, mp *MeeplePlacement) (*tile.Feature, error) {
+	player := e.CurrentPlayer()
 
-	t := e.TilePlacedThisTurn
-	mp := e.DecidedMeeplePlacementThisTurn
+	invalid := func(err error) (*tile.Feature, error) {
+		return nil, &InvalidMeeplePlacementError{
+			Player:    player,
+			Placement: mp,
+			Err:       err,
+		}
+	}
 
-	if t == nil {
-		return
+	if mp.SelectedMeeple.ParentPlayer != player {
+		return invalid(ErrMeepleNotOwned)
 	}
 
-	if mp == nil {
-		return
+	if mp.SelectedMeeple.Feature != nil {
+		return invalid(ErrMeepleInUse)
 	}
 
-	if mp.SelectedMeeple == nil {
-		return
+	if mp.SelectedMeeple.Captor != nil {
+		return invalid(ErrMeepleCaptured)
+	}
+
+	//the dragon comes out of a volcano as soon as it's placed
+	dragonOnTile := t.Reference.HasFeatureType(tile.Volcano)
+
+	if mp.PortalPosition != nil {
+		if !t.Reference.HasFeatureType(tile.Portal) {
+			return invalid(ErrNoPortal)
+		}
+
+		if t = e.GameBoard.Get(*mp.PortalPosition); t == nil {
+			return invalid(ErrFeatureNotOnTile)
+		}
+
+		dragonOnTile = false
+	}
+
+	if dragonOnTile || (e.DragonPosition != nil && *e.DragonPosition == t.Position) {
+		return invalid(ErrDragonOnTile)
 	}
 
 	var newTileFeature *tile.Feature
@@ -253,191 +531,301 @@ func (e *Engine) PlaceMeepleOnFeature() {
 	}
 
 	if newTileFeature == nil {
-		panic("new tile does not have a corresponding feature to place a meeple on")
+		return invalid(ErrFeatureNotOnTile)
 	}
 
-	mp.SelectedMeeple.Feature = newTileFeature
-	newTileFeature.AttachedMeeples = append(newTileFeature.AttachedMeeples, mp.SelectedMeeple)
-}
+	if !mp.SelectedMeeple.Type.CanJoin(newTileFeature.Type) {
+		return invalid(ErrFeatureUnclaimable)
+	}
 
-func (e *Engine) PlaceTile(placement Placement) *tile.Tile {
-	newTile := e.TileFactory.NewTileFromReference(placement.ReferenceTile)
-	e.GameBoard.PlaceTile(placement.Position, newTile)
+	featureChain := newFeatureChain(newTileFeature, e.GameBoard, e.Rules)
+	featureChain.computeMeeples()
 
-	if newTile.Reference.EdgeSignature.Contains(tile.River) {
-		e.lastRiverTile = newTile
+	if mp.PortalPosition != nil && featureChain.isComplete {
+		return invalid(ErrFeatureComplete)
+	}
 
-		if newTile.Reference.EdgeSignature.IsRiverCurving() {
-			e.lastRiverTurn = newTile.Reference.Orientation
-			e.isFirstRiverTurn = false
+	//builders and pigs work for the player's followers, so they can only join features they're on
+	if !mp.SelectedMeeple.Type.Claims() {
+		if !featureChain.hasFollowerOf(player) {
+			return invalid(ErrNoFollowerOnFeature)
 		}
+
+		return newTileFeature, nil
 	}
 
-	return newTile
-}
+	//no one else can be on any part of the feature the meeple is joining
+	if featureChain.hasOwner() {
+		return invalid(ErrFeatureOccupied)
+	}
 
-func (e *Engine) TakeNextTile() (*tile.ReferenceTileGroup, error) {
+	return newTileFeature, nil
+}
 
-	currentDeck := e.Deck
+// sendPrincess
+// the princess sends a follower in her castle home, instead of the player placing a meeple
+func (e *Engine) sendPrincess(t *tile.Tile, mp *MeeplePlacement) error {
+	castle, err := e.validatePrincess(t, mp)
 
-	//must draw from river deck until it's empty
-	if e.RiverDeck.Remaining() > 0 {
-		currentDeck = e.RiverDeck
+	if err != nil {
+		return err
 	}
 
-	rtg, err := currentDeck.Pop()
+	m := mp.PrincessMeeple
+	m.Detach()
 
-	if err != nil {
-		return nil, err
+	if e.FairyMeeple == m {
+		e.FairyMeeple = nil
 	}
 
-	return rtg, nil
+	e.emit(MeeplesReturnedEvent{
+		Turn:    e.TurnCounter,
+		Feature: castle,
+		Meeples: []*Meeple{m},
+	})
+
+	return nil
 }
 
-func (e *Engine) GoToNextTurn() error {
+// validatePrincess
+// checks the follower the princess is sending home is in the castle she's in, and finds her castle on the tile
+func (e *Engine) validatePrincess(t *tile.Tile, mp *MeeplePlacement) (*tile.Feature, error) {
+	m := mp.PrincessMeeple
 
-	if e.Deck.Remaining() == 0 {
-		return errors.New("no more tiles in the deck")
+	invalid := func(err error) (*tile.Feature, error) {
+		return nil, &InvalidMeeplePlacementError{
+			Player:    e.CurrentPlayer(),
+			Placement: mp,
+			Err:       err,
+		}
 	}
 
-	e.TurnCounter++
-	e.CurrentPlayerIndex = (e.CurrentPlayerIndex + 1) % len(e.Players)
-	e.TilePlacedThisTurn = nil
+	castles := princessCastles(t)
 
-	return nil
-}
+	if len(castles) < 1 {
+		return invalid(ErrNoPrincess)
+	}
 
-func (e *Engine) EndGame() {
-	e.GameOver = true
+	if m.Type != Follower || m.Feature == nil {
+		return invalid(ErrPrincessTarget)
+	}
+
+	for _, castle := range castles {
+		featureChain := newFeatureChain(castle, e.GameBoard, e.Rules)
 
-	for _, p := range e.Players {
-		fmt.Println(p.Name, ": ", p.Score)
+		if _, exists := featureChain.FeaturesVisited[m.Feature]; exists {
+			return castle, nil
+		}
 	}
+
+	return invalid(ErrPrincessTarget)
 }
 
-func (e *Engine) restictRiverPlacement() ([]Placement, error) {
+// recallAbbot
+// takes the player's abbot back off its cloister or garden, instead of them placing a meeple.
+// the abbot scores what the feature's worth so far, as if the game had ended
+func (e *Engine) recallAbbot(mp *MeeplePlacement) error {
+	if err := e.validateRecallAbbot(mp); err != nil {
+		return err
+	}
 
-	permittedPlacements := make([]Placement, 0)
-	permittedCurvedPlacements := make([]Placement, 0)
+	m := mp.RecalledAbbot
 
-	for _, placement := range e.CurrentPossibleTilePlacements {
+	featureChain := newFeatureChain(m.Feature, e.GameBoard, e.Rules)
+	featureChain.computeEndGameScore()
+	featureChain.computeMeeples()
 
-		connectedFeatures := placement.ConnectedFeatures
+	score := e.awardFeatureChain(&featureChain)
 
-		for _, cf := range connectedFeatures {
-			dir := cf.EdgeA
+	e.emit(AbbotRecalledEvent{
+		Turn:   e.TurnCounter,
+		Player: m.ParentPlayer,
+		Meeple: m,
+		Score:  score,
+	})
 
-			connectedTilePos := placement.Position.EdgePos(dir)
+	e.emitMeeplesReturned(&featureChain)
 
-			//must be connected to the last tile placed
-			if connectedTilePos != e.lastRiverTile.Position {
-				continue
-			}
+	return nil
+}
 
-			//must be a river connection
-			if cf.FeatureA.Type != tile.River {
-				continue
-			}
+func (e *Engine) validateRecallAbbot(mp *MeeplePlacement) error {
+	player := e.CurrentPlayer()
+	m := mp.RecalledAbbot
 
-			//dont let the river turn the same way twice
-			//if the new tile is curving, keep track of which way it was oriented
-			//the next curved tile must be oriented 180 deg different from this tile
-			if cf.FeatureA.ParentRefenceTileGroup.Orientations[0].EdgeSignature.IsRiverCurving() {
+	invalid := func(err error) error {
+		return &InvalidMeeplePlacementError{
+			Player:    player,
+			Placement: mp,
+			Err:       err,
+		}
+	}
+
+	if m.ParentPlayer != player {
+		return invalid(ErrMeepleNotOwned)
+	}
 
-				//this is the first curve of the river, it can go either way
-				if e.lastRiverTurn == 1 {
-					permittedCurvedPlacements = append(permittedCurvedPlacements, placement)
-					continue
-				}
+	if m.Type != Abbot || m.Feature == nil {
+		return invalid(ErrRecallTarget)
+	}
 
-				// for the first turn, let it turn whatever way it wants
-				if !e.isFirstRiverTurn {
-					//next piece must be 180 degrees out of phase with the last
-					nextCurveOrientation := (e.lastRiverTurn + 180) % 360
+	return nil
+}
 
-					if placement.ReferenceTile.Orientation != nextCurveOrientation {
-						continue
-					}
-				}
+// princessCastles the castles on the tile with a princess in them
+func princessCastles(t *tile.Tile) []*tile.Feature {
+	castles := make([]*tile.Feature, 0, 1)
 
-				permittedCurvedPlacements = append(permittedCurvedPlacements, placement)
-				continue
+	for _, f := range t.Features {
+		if f.Type != tile.Castle {
+			continue
+		}
+
+		for _, rf := range t.Reference.AdjacentFeatures[f.ParentFeature] {
+			if rf.Type == tile.Princess {
+				castles = append(castles, f)
+				break
 			}
+		}
+	}
+
+	return castles
+}
+
+func (e *Engine) PlaceTile(placement Placement) *tile.Tile {
+	newTile := e.TileFactory.NewTileFromReference(placement.ReferenceTile)
+	e.GameBoard.PlaceTile(placement.Position, newTile)
+
+	if newTile.Reference.HasFeatureType(tile.Tower) {
+		e.TowerHeights[placement.Position] = 0
+	}
+
+	if newTile.Reference.EdgeSignature.Contains(tile.River) {
+		e.lastRiverTile = newTile
 
-			permittedPlacements = append(permittedPlacements, placement)
+		if newTile.Reference.EdgeSignature.IsRiverCurving() {
+			e.lastRiverTurn = newTile.Reference.Orientation
+			e.isFirstRiverTurn = false
 		}
 	}
 
-	// second pass to preferentially pick inward facing curves
-	// only relevant when there are multiple valid curve placements
-	if len(permittedCurvedPlacements) > 1 {
+	return newTile
+}
+
+// updatePossibleTilePlacements
+// works out where the held tile can go, which is restricted by the river variant's rules while the river is being placed.
+// when the game's played with hands, it's where each of the tiles in the current player's hand can go
+func (e *Engine) updatePossibleTilePlacements() {
+	rtg := e.HeldRefTileGroup
 
-		magnitudes := make([]struct {
-			p Placement
-			m float64
-		}, 0, len(permittedCurvedPlacements))
+	if rtg == nil {
+		e.CurrentPossibleTilePlacements = e.handTilePlacements(e.CurrentPlayer().hand)
+		return
+	}
 
-		for _, placement := range permittedCurvedPlacements {
-			size := e.GameBoard.TileMatrix.Size()
-			middle := util.Point[int]{X: size / 2, Y: size / 2}
+	e.CurrentPossibleTilePlacements = e.TilePlacementManager.PossibleTilePlacements(rtg)
 
-			// add the direction the river is pointing to the tile's position
-			// then calculate distance to center
-			dir := computeRiverDirection(e, placement)
-			pos := placement.Position.Add(dir)
+	if e.GameBoard.PlacedTileCount > 0 && (e.RiverDeck.Remaining() > 0 || rtg.IsRiverTile()) {
+		e.CurrentPossibleTilePlacements = e.River().RestrictPlacements(e, e.CurrentPossibleTilePlacements)
+	}
+}
 
-			dist := middle.Subtract(pos)
-			mag := dist.Magnitude()
+// discardTile
+// removes a drawn tile from the game for good, onto the discard pile
+func (e *Engine) discardTile(player *Player, rtg *tile.ReferenceTileGroup) {
+	e.discardPile = append(e.discardPile, rtg)
 
-			magnitudes = append(magnitudes, struct {
-				p Placement
-				m float64
-			}{
-				p: placement,
-				m: mag,
-			})
-		}
+	e.emit(TileDiscardedEvent{
+		Turn:   e.TurnCounter,
+		Player: player,
+		Tile:   rtg,
+	})
+}
 
-		sort.Slice(magnitudes, func(i, j int) bool {
-			return magnitudes[i].m < magnitudes[j].m
-		})
+func (e *Engine) TakeNextTile() (*tile.ReferenceTileGroup, error) {
+
+	currentDeck := e.Deck
 
-		permittedPlacements = append(permittedPlacements, magnitudes[0].p)
+	//must draw from river deck until it's empty
+	if e.RiverDeck.Remaining() > 0 {
+		currentDeck = e.RiverDeck
 	}
 
-	return permittedPlacements, nil
+	var rtg *tile.ReferenceTileGroup
+	var err error
+
+	//a replay decides which tiles are drawn, instead of the deck order
+	if len(e.replayDraws) > 0 {
+		rtg, err = currentDeck.Take(e.replayDraws[0])
+		e.replayDraws = e.replayDraws[1:]
+	} else {
+		rtg, err = currentDeck.Pop()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	e.Record.recordDraw(e.TurnCounter, e.CurrentPlayerIndex, rtg)
+
+	e.emit(TileDrawnEvent{
+		Turn:   e.TurnCounter,
+		Player: e.CurrentPlayer(),
+		Tile:   rtg,
+	})
+
+	return rtg, nil
 }
 
-// computeRiverDirection
-// a river tiles pointed direction can be determined by looking at the open-end of the river on the tile
-func computeRiverDirection(eng *Engine, placement Placement) util.Point[int] {
+func (e *Engine) GoToNextTurn() error {
 
-	for edge, feature := range placement.ReferenceTile.EdgeFeatures {
-		if feature.Type != tile.River {
-			continue
-		}
+	if e.Deck.Remaining() == 0 && !e.tilesInHand() {
+		return errors.New("no more tiles in the deck")
+	}
 
-		// river feature
+	e.TurnCounter++
 
-		edgeDir := directions.Direction(edge)
-		neighborTilePos := placement.Position.EdgePos(edgeDir)
-		neighborTile, err := eng.GameBoard.TileMatrix.GetPt(neighborTilePos)
-		if err != nil {
-			continue
-		}
+	//extending a builder gives the same player another turn
+	e.builderTurn = e.builderTriggered
+	e.builderTriggered = false
 
-		if neighborTile != nil {
-			continue
-		}
+	if !e.builderTurn {
+		e.CurrentPlayerIndex = (e.CurrentPlayerIndex + 1) % len(e.Players)
+		e.awardFairyTurnBonus()
+		e.ransomPrisoners()
+	}
 
-		// river feature which is open
+	e.TilePlacedThisTurn = nil
+
+	return nil
+}
 
-		diff := neighborTilePos.Subtract(placement.Position)
+// extendsBuilder
+// whether the tile is part of the road or castle the player's builder is on
+func (e *Engine) extendsBuilder(p *Player, t *tile.Tile) bool {
+	builder := p.placedMeepleOfType(Builder)
 
-		return diff
+	if builder == nil {
+		return false
 	}
 
-	return util.Point[int]{}
+	featureChain := newFeatureChain(builder.Feature, e.GameBoard, e.Rules)
+
+	_, extended := featureChain.TilesVisited[t]
+
+	return extended
+}
+
+func (e *Engine) EndGame() []FeatureScore {
+	e.GameOver = true
+	e.EndGameScores = e.ScoreEndGame()
+
+	e.emit(GameOverEvent{
+		Turn:          e.TurnCounter,
+		EndGameScores: e.EndGameScores,
+	})
+
+	return e.EndGameScores
 }
 
 func (e *Engine) CurrentPlayer() *Player {
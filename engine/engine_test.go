@@ -3,17 +3,38 @@ package engine_test
 import (
 	"beeb/carcassonne/data"
 	"beeb/carcassonne/engine"
-	"math/rand"
+	"beeb/carcassonne/engine/board"
+	"beeb/carcassonne/engine/tile"
+	"errors"
+	"fmt"
 	"testing"
 )
 
-func BenchmarkEngine(b *testing.B) {
+func loadGameData(tb testing.TB, deckFilePath string) *data.GameData {
+	gameData, err := data.LoadGameData("../data/bitmaps", deckFilePath)
+
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return gameData
+}
+
+func newEngine(tb testing.TB, gameData *data.GameData, boardSize int, numPlayers int, seed int64) *engine.Engine {
+	e, err := engine.NewEngine(gameData, boardSize, numPlayers, seed)
+
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return e
+}
 
-	rand.Seed(4)
+func BenchmarkEngine(b *testing.B) {
 
-	gameData := data.LoadGameData("../data/bitmaps", "../data/standard_deck.yml")
+	gameData := loadGameData(b, "../data/standard_deck.yml")
 
-	e1 := engine.NewEngine(gameData, 16, 4)
+	e1 := newEngine(b, gameData, 16, 4, 4)
 
 	steps := (e1.RiverDeck.Remaining() + e1.Deck.Remaining()) * 5
 
@@ -25,3 +46,152 @@ func BenchmarkEngine(b *testing.B) {
 		}
 	}
 }
+
+func BenchmarkEngine_Clone(b *testing.B) {
+	gameData := loadGameData(b, "../data/standard_deck.yml")
+
+	//clone at the start, middle, and end of a game, as the cost grows with the board
+	for _, turns := range []int{1, 36, 72} {
+		b.Run(fmt.Sprint(turns, " turns"), func(b *testing.B) {
+			e := newEngine(b, gameData, 16, 4, 4)
+
+			for !e.GameOver && e.TurnCounter < turns {
+				e.Step()
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				e.Clone()
+			}
+		})
+	}
+}
+
+func TestEngine_EndGame(t *testing.T) {
+
+	gameData := loadGameData(t, "../data/standard_deck.yml")
+
+	e := newEngine(t, gameData, 16, 4, 4)
+
+	for !e.GameOver {
+		e.Step()
+	}
+
+	if len(e.EndGameScores) < 1 {
+		t.Fatal("expected unfinished features to be scored at the end of the game")
+	}
+
+	for _, p := range e.Players {
+		for _, m := range p.Meeples {
+			if m.Feature != nil {
+				t.Errorf("%s has a meeple left on a %s after the game ended", p.Name, m.Feature.Type)
+			}
+		}
+	}
+}
+
+func TestEngine_ValidateMeeplePlacement(t *testing.T) {
+	gameData := loadGameData(t, "../data/standard_deck.yml")
+	e := newEngine(t, gameData, 16, 2, 4)
+
+	p0, p1 := e.Players[0], e.Players[1]
+
+	top := placeTestTile(e, "CastleEndCap", 2, 5, 4)
+	placeTestMeeple(p1, top, tile.Castle)
+
+	//extends the castle p1 is already on
+	bottom := placeTestTile(e, "CastleEndCap", 0, 5, 5)
+	castle := bottom.Reference.EdgeFeatures.GetNorth()
+
+	tests := []struct {
+		name   string
+		meeple *engine.Meeple
+		want   error
+	}{
+		{"occupied", p0.GetAvailableMeepleWithPower(1), engine.ErrFeatureOccupied},
+		{"not owned", p1.GetAvailableMeepleWithPower(1), engine.ErrMeepleNotOwned},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := e.ValidateMeeplePlacement(bottom, &engine.MeeplePlacement{
+				ParentFeature:  castle,
+				SelectedMeeple: tt.meeple,
+			})
+
+			var placementErr *engine.InvalidMeeplePlacementError
+			if !errors.As(err, &placementErr) || !errors.Is(err, tt.want) {
+				t.Errorf("ValidateMeeplePlacement() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestEngine_Seed(t *testing.T) {
+	gameData := loadGameData(t, "../data/standard_deck.yml")
+
+	e1 := newEngine(t, gameData, 16, 4, 7)
+	e2 := newEngine(t, gameData, 16, 4, 7)
+
+	//interleave the games, they should not affect each other
+	for !e1.GameOver || !e2.GameOver {
+		e1.Step()
+		e2.Step()
+	}
+
+	for i := range e1.Players {
+		if e1.Players[i].Score != e2.Players[i].Score {
+			t.Errorf("games with the same seed scored differently for player %d, %d and %d", i, e1.Players[i].Score, e2.Players[i].Score)
+		}
+	}
+
+	if len(e1.GameBoard.Tiles) != len(e2.GameBoard.Tiles) {
+		t.Fatal("games with the same seed placed a different number of tiles")
+	}
+
+	for pos, t1 := range e1.GameBoard.Tiles {
+		t2 := e2.GameBoard.Get(pos)
+
+		if t2 == nil || t1.Reference != t2.Reference {
+			t.Fatalf("games with the same seed have different boards at %s", pos)
+		}
+	}
+}
+
+func TestEngine_UnboundedBoard(t *testing.T) {
+	gameData := loadGameData(t, "../data/mega_deck.yml")
+
+	e := newEngine(t, gameData, board.Unbounded, 4, 3)
+
+	//the basic ai is slow to evaluate the huge fields of a mega deck game
+	for _, p := range e.Players {
+		p.AI = engine.PlayerAITypes["random"](p)
+	}
+
+	for !e.GameOver {
+		e.Step()
+	}
+
+	topLeft, bottomRight := e.GameBoard.Bounds()
+
+	//the mega deck can't fit on the old 16x16 board
+	if bottomRight.X-topLeft.X < 16 && bottomRight.Y-topLeft.Y < 16 {
+		t.Errorf("expected the board to grow past 16 tiles, it spans %s to %s", topLeft, bottomRight)
+	}
+
+	m, origin := e.GameBoard.Matrix()
+	placed := 0
+
+	for pos, tl := range e.GameBoard.Tiles {
+		if m.Get(pos.X-origin.X, pos.Y-origin.Y) != tl {
+			t.Fatalf("cropped matrix is missing the tile at %s", pos)
+		}
+
+		placed++
+	}
+
+	if placed != e.GameBoard.PlacedTileCount {
+		t.Errorf("board holds %d tiles, but counted %d placements", placed, e.GameBoard.PlacedTileCount)
+	}
+}
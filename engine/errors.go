@@ -0,0 +1,83 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrPlayerCount       = errors.New("unsupported number of players")
+	ErrNoTilePlacement   = errors.New("player did not decide on a tile placement")
+	ErrIllegalDragonMove = errors.New("dragon can not move there")
+)
+
+var (
+	ErrActionOutOfTurn      = errors.New("actions can only be applied while a tile is waiting to be placed")
+	ErrIllegalTilePlacement = errors.New("tile can not be placed there")
+)
+
+var (
+	ErrNothingToUndo = errors.New("no turns have been played to undo")
+	ErrNothingToRedo = errors.New("no turns have been undone to redo")
+)
+
+var (
+	ErrUnsupportedStateVersion  = errors.New("unsupported engine state version")
+	ErrInvalidState             = errors.New("invalid engine state")
+	ErrUnsupportedRecordVersion = errors.New("unsupported game record version")
+)
+
+var (
+	ErrNoMeepleAvailable   = errors.New("player has no meeples left to place")
+	ErrMeepleNotOwned      = errors.New("meeple does not belong to the current player")
+	ErrMeepleInUse         = errors.New("meeple is already placed on a feature")
+	ErrMeepleCaptured      = errors.New("meeple is held prisoner by another player")
+	ErrFeatureNotOnTile    = errors.New("tile placed this turn does not have the selected feature")
+	ErrFeatureUnclaimable  = errors.New("meeples can not be placed on this type of feature")
+	ErrFeatureOccupied     = errors.New("feature is already occupied by a meeple")
+	ErrNoFollowerOnFeature = errors.New("player has no follower on the feature for this meeple to join")
+	ErrDragonOnTile        = errors.New("meeples can not be placed on the dragon's tile")
+	ErrNoPortal            = errors.New("tile placed this turn does not have a magic portal")
+	ErrFeatureComplete     = errors.New("meeples can not go through a magic portal to a finished feature")
+	ErrNoPrincess          = errors.New("tile placed this turn does not have a princess")
+	ErrPrincessTarget      = errors.New("the princess can only send home a follower in her castle")
+	ErrNoFairy             = errors.New("the game is not played with the fairy")
+	ErrFairyTarget         = errors.New("the fairy can only be moved next to one of the player's followers on the board")
+	ErrRecallTarget        = errors.New("only an abbot on the board can be recalled")
+	ErrNoTower             = errors.New("there is no tower at the selected position")
+	ErrNoTowerFloors       = errors.New("player has no tower floors left to build")
+	ErrCaptureTarget       = errors.New("a tower can only capture another player's meeple in its reach")
+	ErrOneMeepleAction     = errors.New("only one of placing a meeple, sending the princess home, moving the fairy, recalling an abbot or building a tower can be done in a turn")
+)
+
+// InvalidMeeplePlacementError
+// a meeple placement decided by a player which breaks the rules, Err is one of the reasons above
+type InvalidMeeplePlacementError struct {
+	Player    *Player
+	Placement *MeeplePlacement
+	Err       error
+}
+
+func (err *InvalidMeeplePlacementError) Error() string {
+	return fmt.Sprint("invalid meeple placement by ", err.Player.Name, ": ", err.Err)
+}
+
+func (err *InvalidMeeplePlacementError) Unwrap() error {
+	return err.Err
+}
+
+// DecisionError
+// a player who failed to decide on their turn, like an AI which didn't pick one of the legal placements.
+// the game can't go on until the player decides, Err is why the decision was rejected
+type DecisionError struct {
+	Player *Player
+	Err    error
+}
+
+func (err *DecisionError) Error() string {
+	return fmt.Sprint("decision by ", err.Player.Name, ": ", err.Err)
+}
+
+func (err *DecisionError) Unwrap() error {
+	return err.Err
+}
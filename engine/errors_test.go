@@ -0,0 +1,73 @@
+package engine_test
+
+import (
+	"beeb/carcassonne/data"
+	"beeb/carcassonne/engine"
+	"beeb/carcassonne/engine/turnStage"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadGameData_Errors(t *testing.T) {
+	deckFilePath := filepath.Join(t.TempDir(), "deck.yml")
+	_ = os.WriteFile(deckFilePath, []byte("deck:\n  RoadStraight: 2\n  Moat: 1\n"), 0644)
+
+	_, err := data.LoadGameData("../data/bitmaps", deckFilePath)
+
+	var loadErr *data.LoadError
+	if !errors.As(err, &loadErr) || loadErr.Path != deckFilePath || !errors.Is(err, data.ErrMissingBitmap) {
+		t.Errorf("LoadGameData() with a tile missing its bitmap, error = %v, want %v loading %s", err, data.ErrMissingBitmap, deckFilePath)
+	}
+
+	_ = os.WriteFile(deckFilePath, []byte("deck: [RoadStraight"), 0644)
+
+	if _, err = data.LoadGameData("../data/bitmaps", deckFilePath); !errors.Is(err, data.ErrInvalidDeck) {
+		t.Errorf("LoadGameData() with a malformed deck, error = %v, want %v", err, data.ErrInvalidDeck)
+	}
+
+	if _, err = data.LoadGameData("../data/no_bitmaps", "../data/standard_deck.yml"); !errors.As(err, &loadErr) {
+		t.Errorf("LoadGameData() with a missing bitmap directory, error = %v, want a *data.LoadError", err)
+	}
+}
+
+type undecidedAI struct{}
+
+func (undecidedAI) DeterminePlacement(o *engine.Observation, placementOptions []engine.Placement) (*engine.Placement, *engine.MeeplePlacement) {
+	return nil, nil
+}
+
+func TestEngine_Errors(t *testing.T) {
+	gameData := loadGameData(t, "../data/standard_deck.yml")
+
+	for _, numPlayers := range []int{0, -1} {
+		if _, err := engine.NewEngine(gameData, 16, numPlayers, 1); !errors.Is(err, engine.ErrPlayerCount) {
+			t.Errorf("NewEngine() with %d players, error = %v, want %v", numPlayers, err, engine.ErrPlayerCount)
+		}
+	}
+
+	e := newEngine(t, gameData, 16, 2, 1)
+	if err := e.StepUntilDecision(); err != nil {
+		t.Fatal(err)
+	}
+
+	player := e.CurrentPlayer()
+	player.AI = undecidedAI{}
+
+	err := e.Step()
+
+	var decisionErr *engine.DecisionError
+	if !errors.As(err, &decisionErr) || decisionErr.Player != player || !errors.Is(err, engine.ErrNoTilePlacement) {
+		t.Fatalf("Step() with an AI which doesn't decide, error = %v, want %v", err, engine.ErrNoTilePlacement)
+	}
+
+	if e.TurnStage != turnStage.PlaceTile || e.HeldRefTileGroup == nil {
+		t.Error("the turn should be left waiting on the player's decision")
+	}
+
+	//the game carries on once the player decides
+	if err = e.Apply(e.LegalActions()[0]); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,195 @@
+package engine
+
+import (
+	"beeb/carcassonne/engine/tile"
+	"beeb/carcassonne/util"
+)
+
+// Event
+// something which happened in the engine. subscribers switch on the type to find out what
+type Event interface {
+	event()
+}
+
+// EventSubscriber
+// is told about each event as it happens, while the engine is stepping.
+// the engine is passed along for context, subscribers shouldn't change it
+type EventSubscriber interface {
+	OnEvent(e *Engine, event Event)
+}
+
+// EventSubscriberFunc lets a plain function subscribe to events
+type EventSubscriberFunc func(e *Engine, event Event)
+
+func (f EventSubscriberFunc) OnEvent(e *Engine, event Event) {
+	f(e, event)
+}
+
+type TileDrawnEvent struct {
+	Turn   int
+	Player *Player
+	Tile   *tile.ReferenceTileGroup
+}
+
+// TileDiscardedEvent
+// a drawn tile which couldn't be placed anywhere. it's shuffled back into the deck,
+// unless there have been too many attempts or it's a river tile, then it's removed from the game
+type TileDiscardedEvent struct {
+	Turn       int
+	Player     *Player
+	Tile       *tile.ReferenceTileGroup
+	Reshuffled bool
+}
+
+type TilePlacedEvent struct {
+	Turn   int
+	Player *Player
+	Tile   *tile.Tile
+	Action Action
+}
+
+type MeeplePlacedEvent struct {
+	Turn    int
+	Player  *Player
+	Meeple  *Meeple
+	Feature *tile.Feature
+}
+
+// FeatureCompletedEvent
+// a feature finished by the tile placed this turn. it's sent whether or not anyone owned the feature,
+// the score says who was awarded points for it, if anyone
+type FeatureCompletedEvent struct {
+	Turn  int
+	Chain *FeatureChain
+	Score FeatureScore
+}
+
+// MeeplesReturnedEvent
+// meeples taken off a feature once it's been scored, either when it's completed or at the end of the game,
+// or a follower the princess sent home, or an abbot recalled by its player
+type MeeplesReturnedEvent struct {
+	Turn    int
+	Feature *tile.Feature
+	Meeples []*Meeple
+}
+
+// GoodsCollectedEvent
+// goods from a castle, collected by the player who finished it
+type GoodsCollectedEvent struct {
+	Turn   int
+	Player *Player
+	Chain  *FeatureChain
+	Goods  map[tile.FeatureType]int
+}
+
+// DragonMovedEvent
+// the dragon moving onto a tile and eating every meeple on it, Player is who moved it.
+// From is nil when the dragon first appears, at the volcano it came out of
+type DragonMovedEvent struct {
+	Turn   int
+	Player *Player
+	From   *util.Point[int]
+	To     util.Point[int]
+	Eaten  []*Meeple
+}
+
+type FairyMovedEvent struct {
+	Turn   int
+	Player *Player
+	Meeple *Meeple
+}
+
+// FairyScoredEvent
+// the turn bonus scored by the owner of the follower the fairy's next to, at the start of their turn.
+// the fairy bonus for a scored feature is part of the feature's score instead
+type FairyScoredEvent struct {
+	Turn   int
+	Player *Player
+	Meeple *Meeple
+	Score  int
+}
+
+// AbbotRecalledEvent
+// an abbot taken back off its cloister or garden before it was finished, scoring what it was worth so far.
+// the abbot is returned to the player, which is sent as a MeeplesReturnedEvent afterwards
+type AbbotRecalledEvent struct {
+	Turn   int
+	Player *Player
+	Meeple *Meeple
+	Score  FeatureScore
+}
+
+// TowerBuiltEvent
+// a floor built on the tower at the position, instead of placing a meeple. Captured is the meeple the tower took prisoner,
+// if there was one in reach
+type TowerBuiltEvent struct {
+	Turn     int
+	Player   *Player
+	Position util.Point[int]
+	Height   int
+	Captured *Meeple
+}
+
+// MeepleRansomedEvent
+// a captured meeple bought back by its owner at the start of their turn, the ransom goes to the player who was holding it
+type MeepleRansomedEvent struct {
+	Turn   int
+	Player *Player
+	Captor *Player
+	Meeple *Meeple
+	Ransom int
+}
+
+type TurnPassedEvent struct {
+	Turn           int
+	Player         *Player
+	PreviousPlayer *Player
+}
+
+type GameOverEvent struct {
+	Turn          int
+	EndGameScores []FeatureScore
+}
+
+func (TileDrawnEvent) event()        {}
+func (TileDiscardedEvent) event()    {}
+func (TilePlacedEvent) event()       {}
+func (MeeplePlacedEvent) event()     {}
+func (FeatureCompletedEvent) event() {}
+func (MeeplesReturnedEvent) event()  {}
+func (GoodsCollectedEvent) event()   {}
+func (DragonMovedEvent) event()      {}
+func (FairyMovedEvent) event()       {}
+func (FairyScoredEvent) event()      {}
+func (AbbotRecalledEvent) event()    {}
+func (TowerBuiltEvent) event()       {}
+func (MeepleRansomedEvent) event()   {}
+func (TurnPassedEvent) event()       {}
+func (GameOverEvent) event()         {}
+
+type subscription struct {
+	subscriber EventSubscriber
+}
+
+// Subscribe
+// sends every event from now on to the subscriber, until the returned function is called to unsubscribe.
+// subscribers aren't copied to clones of the engine
+func (e *Engine) Subscribe(subscriber EventSubscriber) func() {
+	s := &subscription{subscriber: subscriber}
+	e.subscriptions = append(e.subscriptions, s)
+
+	return func() {
+		for i, es := range e.subscriptions {
+			if es == s {
+				e.subscriptions = append(e.subscriptions[:i:i], e.subscriptions[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+func (e *Engine) emit(event Event) {
+	for _, s := range e.subscriptions {
+		s.subscriber.OnEvent(e, event)
+	}
+}
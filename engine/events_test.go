@@ -0,0 +1,111 @@
+package engine_test
+
+import (
+	"beeb/carcassonne/engine"
+	"beeb/carcassonne/engine/tile"
+	"testing"
+)
+
+func TestEngine_Subscribe(t *testing.T) {
+	gameData := loadGameData(t, "../data/standard_deck.yml")
+	e := newEngine(t, gameData, 16, 4, 6)
+
+	counts := make(map[string]int)
+	scoreAwarded := 0
+	meeplesOut := 0
+	var lastEvent engine.Event
+
+	e.Subscribe(engine.EventSubscriberFunc(func(e *engine.Engine, event engine.Event) {
+		lastEvent = event
+
+		switch ev := event.(type) {
+		case engine.TileDrawnEvent:
+			counts["drawn"]++
+		case engine.TileDiscardedEvent:
+			counts["discarded"]++
+		case engine.TilePlacedEvent:
+			counts["placed"]++
+		case engine.MeeplePlacedEvent:
+			meeplesOut++
+		case engine.FeatureCompletedEvent:
+			scoreAwarded += ev.Score.Score * len(ev.Score.Owners)
+			counts[ev.Score.Type.String()]++
+		case engine.MeeplesReturnedEvent:
+			meeplesOut -= len(ev.Meeples)
+		case engine.TurnPassedEvent:
+			counts["passed"]++
+		case engine.GameOverEvent:
+			counts["over"]++
+
+			for _, fs := range ev.EndGameScores {
+				scoreAwarded += fs.Score * len(fs.Owners)
+			}
+		}
+	}))
+
+	for !e.GameOver {
+		e.Step()
+	}
+
+	if counts["placed"] != e.GameBoard.PlacedTileCount {
+		t.Errorf("%d tile placed events for %d tiles on the board", counts["placed"], e.GameBoard.PlacedTileCount)
+	}
+
+	if counts["drawn"] != counts["placed"]+counts["discarded"] {
+		t.Errorf("%d tiles drawn, but %d placed and %d discarded", counts["drawn"], counts["placed"], counts["discarded"])
+	}
+
+	if counts["passed"] != e.TurnCounter {
+		t.Errorf("%d turn passed events for %d turns", counts["passed"], e.TurnCounter)
+	}
+
+	//markers and the river aren't features in their own right
+	for _, featureType := range []tile.FeatureType{tile.Shield, tile.River, tile.Farm} {
+		if counts[featureType.String()] != 0 {
+			t.Errorf("%d %s features were completed, expected none", counts[featureType.String()], featureType)
+		}
+	}
+
+	if _, isGameOver := lastEvent.(engine.GameOverEvent); !isGameOver || counts["over"] != 1 {
+		t.Error("expected the game over event once, as the last event")
+	}
+
+	//every meeple comes back at the end of the game
+	if meeplesOut != 0 {
+		t.Errorf("%d meeples were placed and never returned", meeplesOut)
+	}
+
+	totalScore := 0
+	for _, p := range e.Players {
+		totalScore += p.Score
+	}
+
+	if scoreAwarded != totalScore {
+		t.Errorf("events awarded %d points, players scored %d", scoreAwarded, totalScore)
+	}
+}
+
+func TestEngine_Unsubscribe(t *testing.T) {
+	gameData := loadGameData(t, "../data/standard_deck.yml")
+	e := newEngine(t, gameData, 16, 4, 6)
+
+	events := 0
+	unsubscribe := e.Subscribe(engine.EventSubscriberFunc(func(e *engine.Engine, event engine.Event) {
+		events++
+	}))
+
+	if err := e.StepUntilDecision(); err != nil {
+		t.Fatal(err)
+	}
+	unsubscribe()
+
+	seen := events
+
+	for !e.GameOver {
+		e.Step()
+	}
+
+	if seen == 0 || events != seen {
+		t.Errorf("expected events only while subscribed, got %d before and %d after unsubscribing", seen, events-seen)
+	}
+}
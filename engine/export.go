@@ -1,124 +1,831 @@
 package engine
 
 import (
+	"beeb/carcassonne/data"
+	"beeb/carcassonne/engine/board"
+	"beeb/carcassonne/engine/deck"
 	"beeb/carcassonne/engine/tile"
+	"beeb/carcassonne/engine/turnStage"
 	"beeb/carcassonne/util"
 	"encoding/json"
+	"fmt"
 	"image/color"
+	"math/rand"
 	"os"
+
+	"github.com/google/uuid"
 )
 
+// EngineStateVersion
+// bump this whenever the shape of EngineState changes
+const EngineStateVersion = 6
+
 type StateFeature struct {
-	Id   string
 	Type string
 }
 
+// StateFeatureRef
+// points to one of the features of a tile on the board, by the index of the feature on the tile
+type StateFeatureRef struct {
+	Position util.Point[int]
+	Index    int
+}
+
 type StateTile struct {
-	Id           string
 	Name         string
+	Orientation  int
 	Position     util.Point[int]
 	Features     []StateFeature
-	EdgeFeatures []string
+	EdgeFeatures []int
 }
 
 type StateMeeple struct {
-	Id    string
+	Id string
+	//empty for a follower
+	Type  string `json:",omitempty"`
 	Power int
+	//nil when the meeple is in the player's supply
+	Feature *StateFeatureRef
+}
+
+// StateMeepleRef
+// points to a meeple by the index of its player, and its index in the player's meeples
+type StateMeepleRef struct {
+	Player int
+	Index  int
 }
 
 type StatePlayer struct {
 	Id      string
 	Name    string
-	Color   color.Color
+	Color   color.RGBA
 	Score   int
-	Meeples []string
+	AI      string
+	Meeples []StateMeeple
+	Goods   map[string]int `json:",omitempty"`
+
+	//the tower floors the player has left to build, and the other players' meeples they're holding prisoner
+	TowerFloors int              `json:",omitempty"`
+	Prisoners   []StateMeepleRef `json:",omitempty"`
+
+	//the tiles in the player's hand, when the game's played with hands
+	Hand []string `json:",omitempty"`
 }
 
 type StateDeck struct {
+	Tiles []string
+}
+
+type StateAction struct {
+	Name        string
+	Orientation int
+	Position    util.Point[int]
+	//index of the reference tile's feature the meeple goes on, nil for no meeple
+	MeepleFeature *int
+	MeepleType    string `json:",omitempty"`
+	MeeplePower   int    `json:",omitempty"`
+	//the tile the meeple went on through a magic portal, MeepleFeature is then the index of that tile's feature
+	PortalPosition *util.Point[int] `json:",omitempty"`
+	PrincessMeeple *StateMeepleRef  `json:",omitempty"`
+	FairyMeeple    *StateMeepleRef  `json:",omitempty"`
+	RecalledAbbot  *StateMeepleRef  `json:",omitempty"`
+	TowerPosition  *util.Point[int] `json:",omitempty"`
+	CapturedMeeple *StateMeepleRef  `json:",omitempty"`
+}
+
+type StateTurn struct {
+	Counter       int
+	Stage         turnStage.TurnStage
+	CurrentPlayer int
+	HeldTile      string
+	TilePlaced    *util.Point[int]
+	DecidedAction *StateAction
+
+	BuilderTurn      bool `json:",omitempty"`
+	BuilderTriggered bool `json:",omitempty"`
+}
+
+// StateDragon
+// where the dragon is, and the tiles it's been on while it's moving this turn
+type StateDragon struct {
+	Position util.Point[int]
+	Path     []util.Point[int] `json:",omitempty"`
+}
+
+// StateFairy
+// where the fairy is, and the follower she's with, if she's with anyone
+type StateFairy struct {
+	Position util.Point[int]
+	Meeple   *StateMeepleRef `json:",omitempty"`
+}
+
+// StateTower a tower on the board, and how many floors have been built on it
+type StateTower struct {
+	Position util.Point[int]
+	Height   int
 }
 
+type StateRiver struct {
+	IsFirstTurn bool
+	LastTurn    int
+	LastTile    *util.Point[int]
+}
+
+// EngineState
+// everything needed to rebuild an engine part way through a game, apart from the game data it was built from
 type EngineState struct {
-	Players map[string]StatePlayer
-	Meeples map[string]StateMeeple
-	Tiles   map[string]StateTile
+	Version   int
+	Seed      int64
+	RandDraws uint64
+	BoardSize int
+	Rules     *data.RuleSet   `json:",omitempty"`
+	Setup     *data.GameSetup `json:",omitempty"`
+	GameOver  bool
+	Players   []StatePlayer
+	Tiles     []StateTile
+	RiverDeck StateDeck
+	Deck      StateDeck
+	Discarded []string `json:",omitempty"`
+	Turn      StateTurn
+	River     StateRiver
+	Dragon    *StateDragon `json:",omitempty"`
+	Fairy     *StateFairy  `json:",omitempty"`
+	Towers    []StateTower `json:",omitempty"`
 }
 
 func NewEngineState(e *Engine) *EngineState {
 	state := &EngineState{}
 
-	state.Players = make(map[string]StatePlayer)
-	state.Meeples = make(map[string]StateMeeple)
+	state.Version = EngineStateVersion
+	state.Seed = e.Seed
+	state.RandDraws = e.randSource.draws
+	state.BoardSize = e.BoardSize
+	state.Rules = e.Rules
+	state.Setup = e.Setup
+	state.GameOver = e.GameOver
 
-	for _, p := range e.Players {
-		id := p.Id.String()
+	state.Players = make([]StatePlayer, len(e.Players))
 
+	for i, p := range e.Players {
 		player := StatePlayer{
-			Id:    id,
+			Id:    p.Id.String(),
 			Name:  p.Name,
-			Color: p.Color,
+			Color: color.RGBAModel.Convert(p.Color).(color.RGBA),
 			Score: p.Score,
+			AI:    PlayerAITypeName(p.AI),
+
+			TowerFloors: p.TowerFloors,
 		}
 
-		player.Meeples = make([]string, len(p.Meeples))
+		player.Meeples = make([]StateMeeple, len(p.Meeples))
 
-		for i, m := range p.Meeples {
-			id := m.Id.String()
-			state.Meeples[id] = StateMeeple{
-				Id:    id,
-				Power: m.Power,
+		for j, m := range p.Meeples {
+			player.Meeples[j] = StateMeeple{
+				Id:      m.Id.String(),
+				Type:    stateMeepleType(m.Type),
+				Power:   m.Power,
+				Feature: newStateFeatureRef(m.Feature),
 			}
-
-			player.Meeples[i] = id
 		}
 
-		state.Players[id] = player
-	}
+		for goodsType, count := range p.Goods {
+			if player.Goods == nil {
+				player.Goods = make(map[string]int, len(p.Goods))
+			}
 
-	state.Tiles = make(map[string]StateTile)
+			player.Goods[goodsType.String()] = count
+		}
 
-	e.GameBoard.TileMatrix.Iterate(func(t *tile.Tile, x int, y int, idx int) {
+		for _, m := range p.Prisoners {
+			player.Prisoners = append(player.Prisoners, *e.newStateMeepleRef(m))
+		}
 
-		if t == nil {
-			return
+		for _, rtg := range p.hand {
+			player.Hand = append(player.Hand, rtg.Name)
 		}
 
-		id := t.Id.String()
+		state.Players[i] = player
+	}
+
+	state.Tiles = make([]StateTile, 0, e.GameBoard.PlacedTileCount)
+
+	for _, t := range e.GameBoard.PlacedTiles() {
 		st := StateTile{
-			Id:       id,
-			Name:     t.Reference.Name,
-			Position: t.Position,
+			Name:        t.Reference.Name,
+			Orientation: t.Reference.Orientation,
+			Position:    t.Position,
 		}
 
 		st.Features = make([]StateFeature, len(t.Features))
 
 		for i, f := range t.Features {
 			st.Features[i] = StateFeature{
-				Id:   f.Id.String(),
 				Type: f.Type.String(),
 			}
 		}
 
-		st.EdgeFeatures = make([]string, len(t.EdgeFeatures))
+		st.EdgeFeatures = make([]int, len(t.EdgeFeatures))
 
 		for i, f := range t.EdgeFeatures {
-			st.EdgeFeatures[i] = f.Id.String()
+			st.EdgeFeatures[i] = featureIndex(t.Features, f)
+		}
+
+		state.Tiles = append(state.Tiles, st)
+
+		if height, exists := e.TowerHeights[t.Position]; exists {
+			state.Towers = append(state.Towers, StateTower{
+				Position: t.Position,
+				Height:   height,
+			})
+		}
+	}
+
+	state.RiverDeck = newStateDeck(e.RiverDeck)
+	state.Deck = newStateDeck(e.Deck)
+
+	for _, rtg := range e.discardPile {
+		state.Discarded = append(state.Discarded, rtg.Name)
+	}
+
+	state.Turn = StateTurn{
+		Counter:          e.TurnCounter,
+		Stage:            e.TurnStage,
+		CurrentPlayer:    e.CurrentPlayerIndex,
+		BuilderTurn:      e.builderTurn,
+		BuilderTriggered: e.builderTriggered,
+	}
+
+	if e.HeldRefTileGroup != nil {
+		state.Turn.HeldTile = e.HeldRefTileGroup.Name
+	}
+
+	if e.TilePlacedThisTurn != nil {
+		pos := e.TilePlacedThisTurn.Position
+		state.Turn.TilePlaced = &pos
+	}
+
+	if e.DecidedActionThisTurn != nil {
+		state.Turn.DecidedAction = e.newStateAction(*e.DecidedActionThisTurn)
+	}
+
+	if e.DragonPosition != nil {
+		state.Dragon = &StateDragon{
+			Position: *e.DragonPosition,
+			Path:     e.dragonPath,
 		}
+	}
 
-		state.Tiles[st.Id] = st
-	})
+	if e.FairyPosition != nil {
+		state.Fairy = &StateFairy{
+			Position: *e.FairyPosition,
+			Meeple:   e.newStateMeepleRef(e.FairyMeeple),
+		}
+	}
+
+	state.River = StateRiver{
+		IsFirstTurn: e.isFirstRiverTurn,
+		LastTurn:    e.lastRiverTurn,
+	}
+
+	if e.lastRiverTile != nil {
+		pos := e.lastRiverTile.Position
+		state.River.LastTile = &pos
+	}
 
 	return state
 }
 
-func (e *Engine) ExportEngineState() {
+func (e *Engine) newStateAction(a Action) *StateAction {
+	action := &StateAction{
+		Name:           a.ReferenceTile.Name,
+		Orientation:    a.ReferenceTile.Orientation,
+		Position:       a.Position,
+		MeepleType:     stateMeepleType(a.MeepleType),
+		MeeplePower:    a.MeeplePower,
+		PortalPosition: a.PortalPosition,
+		PrincessMeeple: e.newStateMeepleRef(a.PrincessMeeple),
+		FairyMeeple:    e.newStateMeepleRef(a.FairyMeeple),
+		RecalledAbbot:  e.newStateMeepleRef(a.RecalledAbbot),
+		TowerPosition:  a.TowerPosition,
+		CapturedMeeple: e.newStateMeepleRef(a.CapturedMeeple),
+	}
+
+	if a.PlacesMeeple() {
+		features := a.ReferenceTile.Features
+
+		if a.PortalPosition != nil {
+			features = e.GameBoard.Get(*a.PortalPosition).Reference.Features
+		}
+
+		i := featureIndex(features, a.MeepleFeature)
+		action.MeepleFeature = &i
+	}
+
+	return action
+}
+
+func (e *Engine) newStateMeepleRef(m *Meeple) *StateMeepleRef {
+	if m == nil {
+		return nil
+	}
+
+	for i, p := range e.Players {
+		for j, pm := range p.Meeples {
+			if pm == m {
+				return &StateMeepleRef{Player: i, Index: j}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (e *Engine) stateMeepleRef(ref *StateMeepleRef) (*Meeple, error) {
+	if ref == nil {
+		return nil, nil
+	}
+
+	if ref.Player < 0 || ref.Player >= len(e.Players) {
+		return nil, fmt.Errorf("%w: meeple of player %d out of %d players", ErrInvalidState, ref.Player, len(e.Players))
+	}
+
+	p := e.Players[ref.Player]
+
+	if ref.Index < 0 || ref.Index >= len(p.Meeples) {
+		return nil, fmt.Errorf("%w: player %s has no meeple %d", ErrInvalidState, p.Name, ref.Index)
+	}
+
+	return p.Meeples[ref.Index], nil
+}
+
+// followers are left out, as they're the usual meeple
+func stateMeepleType(meepleType MeepleType) string {
+	if meepleType == Follower {
+		return ""
+	}
+
+	return meepleType.String()
+}
+
+func parseStateMeepleType(name string) (MeepleType, error) {
+	if name == "" {
+		return Follower, nil
+	}
+
+	meepleType, exists := ParseMeepleType(name)
+
+	if !exists {
+		return Follower, fmt.Errorf("%w: unknown meeple type %s", ErrInvalidState, name)
+	}
+
+	return meepleType, nil
+}
+
+func newStateFeatureRef(f *tile.Feature) *StateFeatureRef {
+	if f == nil {
+		return nil
+	}
+
+	return &StateFeatureRef{
+		Position: f.ParentTile.Position,
+		Index:    featureIndex(f.ParentTile.Features, f),
+	}
+}
+
+func newStateDeck(d *deck.Deck) StateDeck {
+	stateDeck := StateDeck{}
+	stateDeck.Tiles = make([]string, len(d.Tiles))
+
+	for i, rtg := range d.Tiles {
+		stateDeck.Tiles[i] = rtg.Name
+	}
+
+	return stateDeck
+}
+
+func featureIndex(features []*tile.Feature, f *tile.Feature) int {
+	for i, tf := range features {
+		if tf == f {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// LoadEngineState
+// rebuilds the engine the state was saved from, the game data must have been loaded from the same tiles and deck
+func LoadEngineState(gameData *data.GameData, state *EngineState) (*Engine, error) {
+	if state.Version != EngineStateVersion {
+		return nil, fmt.Errorf("%w: version %d, expected %d", ErrUnsupportedStateVersion, state.Version, EngineStateVersion)
+	}
+
+	e := &Engine{}
+
+	e.Seed = state.Seed
+	e.BoardSize = state.BoardSize
+	e.GameData = gameData
+	e.Rules = state.Rules
+
+	//states saved before rules were configurable were played by the standard rules
+	if e.Rules == nil {
+		e.Rules = data.DefaultRuleSet()
+	}
+
+	if err := e.Rules.Validate(); err != nil {
+		return nil, err
+	}
+
+	e.TileFactory = &tile.TileFactory{}
+	e.TilePlacementManager = NewTilePlacementManager(e)
+
+	e.randSource = newCountedSource(state.Seed, state.RandDraws)
+	e.Rand = rand.New(e.randSource)
+
+	e.GameOver = state.GameOver
+	e.GameBoard = board.NewBoard(state.BoardSize)
+
+	for _, st := range state.Tiles {
+		rt, err := e.stateReferenceTile(st.Name, st.Orientation)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if !e.GameBoard.IsInBounds(st.Position) {
+			return nil, fmt.Errorf("%w: tile %s at %s is off the board", ErrInvalidState, st.Name, st.Position)
+		}
+
+		e.GameBoard.PlaceTile(st.Position, e.TileFactory.NewTileFromReference(rt))
+	}
+
+	e.Players = make([]*Player, len(state.Players))
+
+	for i, sp := range state.Players {
+		p, err := e.statePlayer(sp)
+
+		if err != nil {
+			return nil, err
+		}
+
+		e.Players[i] = p
+	}
+
+	e.Setup = state.Setup
+
+	if e.Setup == nil {
+		e.Setup = setupFromPlayers(e.Players)
+	}
+
+	if len(e.Setup.Players) != len(e.Players) {
+		return nil, fmt.Errorf("%w: %d players set up, but %d playing", ErrInvalidState, len(e.Setup.Players), len(e.Players))
+	}
+
+	if err := validateSetup(e.Setup); err != nil {
+		return nil, err
+	}
+
+	var err error
+
+	if e.RiverDeck, err = e.stateDeck(state.RiverDeck); err != nil {
+		return nil, err
+	}
+
+	if e.Deck, err = e.stateDeck(state.Deck); err != nil {
+		return nil, err
+	}
+
+	discardPile, err := e.stateDeck(StateDeck{Tiles: state.Discarded})
+
+	if err != nil {
+		return nil, err
+	}
+
+	e.discardPile = discardPile.Tiles
+
+	e.isFirstRiverTurn = state.River.IsFirstTurn
+	e.lastRiverTurn = state.River.LastTurn
+
+	if state.River.LastTile != nil {
+		if e.lastRiverTile, err = e.stateTileAt(*state.River.LastTile); err != nil {
+			return nil, err
+		}
+	}
+
+	if state.Dragon != nil {
+		pos := state.Dragon.Position
+		e.DragonPosition = &pos
+		e.dragonPath = state.Dragon.Path
+	}
+
+	if state.Fairy != nil {
+		pos := state.Fairy.Position
+		e.FairyPosition = &pos
+
+		if e.FairyMeeple, err = e.stateMeepleRef(state.Fairy.Meeple); err != nil {
+			return nil, err
+		}
+	}
+
+	e.TowerHeights = make(map[util.Point[int]]int, len(state.Towers))
+
+	for _, st := range state.Towers {
+		if _, err = e.stateTileAt(st.Position); err != nil {
+			return nil, err
+		}
+
+		e.TowerHeights[st.Position] = st.Height
+	}
+
+	//prisoners belong to the other players, so they're only found once every player's meeples are loaded
+	for i, sp := range state.Players {
+		for _, ref := range sp.Prisoners {
+			m, err := e.stateMeepleRef(&ref)
+
+			if err != nil {
+				return nil, err
+			}
+
+			m.Captor = e.Players[i]
+			e.Players[i].Prisoners = append(e.Players[i].Prisoners, m)
+		}
+	}
+
+	if err = e.loadStateTurn(state.Turn); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+func (e *Engine) loadStateTurn(turn StateTurn) error {
+	if turn.CurrentPlayer < 0 || turn.CurrentPlayer >= len(e.Players) {
+		return fmt.Errorf("%w: current player %d out of %d players", ErrInvalidState, turn.CurrentPlayer, len(e.Players))
+	}
+
+	e.TurnCounter = turn.Counter
+	e.TurnStage = turn.Stage
+	e.CurrentPlayerIndex = turn.CurrentPlayer
+	e.builderTurn = turn.BuilderTurn
+	e.builderTriggered = turn.BuilderTriggered
+
+	var err error
+
+	if turn.HeldTile != "" {
+		rtg, exists := e.GameData.ReferenceTileGroups[turn.HeldTile]
+
+		if !exists {
+			return fmt.Errorf("%w: unknown tile %s", ErrInvalidState, turn.HeldTile)
+		}
+
+		e.HeldRefTileGroup = rtg
+		e.updatePossibleTilePlacements()
+	} else if e.playsHands() && turn.Stage == turnStage.PlaceTile {
+		//the player's picking from their hand
+		e.updatePossibleTilePlacements()
+	}
+
+	if turn.TilePlaced != nil {
+		if e.TilePlacedThisTurn, err = e.stateTileAt(*turn.TilePlaced); err != nil {
+			return err
+		}
+	}
+
+	if turn.DecidedAction != nil {
+		action, err := e.stateAction(*turn.DecidedAction)
+
+		if err != nil {
+			return err
+		}
+
+		e.DecidedActionThisTurn = &action
+		e.DecidedMeeplePlacementThisTurn = e.meeplePlacementForAction(action)
+	}
+
+	return nil
+}
+
+func (e *Engine) stateAction(sa StateAction) (Action, error) {
+	rt, err := e.stateReferenceTile(sa.Name, sa.Orientation)
+
+	if err != nil {
+		return Action{}, err
+	}
+
+	meepleType, err := parseStateMeepleType(sa.MeepleType)
+
+	if err != nil {
+		return Action{}, err
+	}
+
+	action := Action{
+		Position:       sa.Position,
+		ReferenceTile:  rt,
+		MeepleType:     meepleType,
+		MeeplePower:    sa.MeeplePower,
+		PortalPosition: sa.PortalPosition,
+	}
+
+	if sa.MeepleFeature != nil {
+		features := rt.Features
+
+		if sa.PortalPosition != nil {
+			t, err := e.stateTileAt(*sa.PortalPosition)
+
+			if err != nil {
+				return Action{}, err
+			}
+
+			features = t.Reference.Features
+		}
+
+		if *sa.MeepleFeature < 0 || *sa.MeepleFeature >= len(features) {
+			return Action{}, fmt.Errorf("%w: tile %s has no feature %d", ErrInvalidState, sa.Name, *sa.MeepleFeature)
+		}
+
+		action.MeepleFeature = features[*sa.MeepleFeature]
+	}
+
+	if action.PrincessMeeple, err = e.stateMeepleRef(sa.PrincessMeeple); err != nil {
+		return Action{}, err
+	}
+
+	if action.FairyMeeple, err = e.stateMeepleRef(sa.FairyMeeple); err != nil {
+		return Action{}, err
+	}
+
+	if action.RecalledAbbot, err = e.stateMeepleRef(sa.RecalledAbbot); err != nil {
+		return Action{}, err
+	}
+
+	action.TowerPosition = sa.TowerPosition
+
+	if action.CapturedMeeple, err = e.stateMeepleRef(sa.CapturedMeeple); err != nil {
+		return Action{}, err
+	}
+
+	return action, nil
+}
+
+func (e *Engine) statePlayer(sp StatePlayer) (*Player, error) {
+	p := NewPlayer(sp.Name, sp.Color, 0)
+
+	id, err := uuid.Parse(sp.Id)
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: player %s id: %v", ErrInvalidState, sp.Name, err)
+	}
+
+	p.Id = id
+	p.Score = sp.Score
+	p.TowerFloors = sp.TowerFloors
+
+	for _, name := range sp.Hand {
+		rtg, exists := e.GameData.ReferenceTileGroups[name]
+
+		if !exists {
+			return nil, fmt.Errorf("%w: unknown tile %s in the hand of player %s", ErrInvalidState, name, sp.Name)
+		}
+
+		p.hand = append(p.hand, rtg)
+	}
+
+	for name, count := range sp.Goods {
+		goodsType, exists := tile.ParseFeatureType(name)
+
+		if !exists || !goodsType.IsGoods() {
+			return nil, fmt.Errorf("%w: unknown goods %s for player %s", ErrInvalidState, name, sp.Name)
+		}
+
+		p.Goods[goodsType] = count
+	}
+
+	if sp.AI != "" {
+		newAI, exists := PlayerAITypes[sp.AI]
+
+		if !exists {
+			return nil, fmt.Errorf("%w: unknown AI %s for player %s", ErrInvalidState, sp.AI, sp.Name)
+		}
+
+		p.AI = newAI(p)
+	}
+
+	p.Meeples = make([]*Meeple, len(sp.Meeples))
+
+	for i, sm := range sp.Meeples {
+		meepleId, err := uuid.Parse(sm.Id)
+
+		if err != nil {
+			return nil, fmt.Errorf("%w: meeple %s id: %v", ErrInvalidState, sm.Id, err)
+		}
+
+		meepleType, err := parseStateMeepleType(sm.Type)
+
+		if err != nil {
+			return nil, err
+		}
+
+		m := &Meeple{
+			Id:           meepleId,
+			Type:         meepleType,
+			Power:        sm.Power,
+			ParentPlayer: p,
+		}
+
+		if sm.Feature != nil {
+			t, err := e.stateTileAt(sm.Feature.Position)
+
+			if err != nil {
+				return nil, err
+			}
+
+			if sm.Feature.Index < 0 || sm.Feature.Index >= len(t.Features) {
+				return nil, fmt.Errorf("%w: tile at %s has no feature %d", ErrInvalidState, sm.Feature.Position, sm.Feature.Index)
+			}
+
+			m.Feature = t.Features[sm.Feature.Index]
+			m.Feature.AttachedMeeples = append(m.Feature.AttachedMeeples, m)
+		}
+
+		p.Meeples[i] = m
+	}
+
+	return p, nil
+}
+
+func (e *Engine) stateDeck(sd StateDeck) (*deck.Deck, error) {
+	d := &deck.Deck{}
+
+	for _, name := range sd.Tiles {
+		rtg, exists := e.GameData.ReferenceTileGroups[name]
+
+		if !exists {
+			return nil, fmt.Errorf("%w: unknown tile %s", ErrInvalidState, name)
+		}
+
+		d.Append(rtg)
+	}
+
+	return d, nil
+}
+
+func (e *Engine) stateReferenceTile(name string, orientation int) (*tile.ReferenceTile, error) {
+	rtg, exists := e.GameData.ReferenceTileGroups[name]
+
+	if !exists {
+		return nil, fmt.Errorf("%w: unknown tile %s", ErrInvalidState, name)
+	}
+
+	for _, rt := range rtg.Orientations {
+		if rt.Orientation == orientation {
+			return rt, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: tile %s has no orientation %d", ErrInvalidState, name, orientation)
+}
+
+func (e *Engine) stateTileAt(pos util.Point[int]) (*tile.Tile, error) {
+	t := e.GameBoard.Get(pos)
+
+	if t == nil {
+		return nil, fmt.Errorf("%w: no tile at %s", ErrInvalidState, pos)
+	}
+
+	return t, nil
+}
+
+func (e *Engine) ExportEngineState() error {
+	return e.WriteEngineState("./state.json")
+}
+
+func (e *Engine) WriteEngineState(path string) error {
 
 	state := NewEngineState(e)
 
 	jsonBytes, err := json.MarshalIndent(state, "", "\t")
 
 	if err != nil {
-		panic(err)
+		return err
+	}
+
+	return os.WriteFile(path, jsonBytes, 0644)
+}
+
+// ReadEngineState
+// reads a state written by WriteEngineState, use LoadEngineState to turn it back into an engine
+func ReadEngineState(path string) (*EngineState, error) {
+	jsonBytes, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	state := &EngineState{}
+
+	if err = json.Unmarshal(jsonBytes, state); err != nil {
+		return nil, err
 	}
 
-	os.WriteFile("./state.json", jsonBytes, 0644)
+	return state, nil
 }
@@ -0,0 +1,60 @@
+package engine_test
+
+import (
+	"beeb/carcassonne/engine"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestLoadEngineState(t *testing.T) {
+	gameData := loadGameData(t, "../data/standard_deck.yml")
+
+	//stop at different stages of a turn, and both during and after the river
+	for _, steps := range []int{0, 7, 38, 121, 203} {
+		t.Run(fmt.Sprint(steps, " steps"), func(t *testing.T) {
+			e1 := newEngine(t, gameData, 16, 4, 4)
+
+			for i := 0; i < steps; i++ {
+				e1.Step()
+			}
+
+			stateJson, err := json.Marshal(engine.NewEngineState(e1))
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			state := &engine.EngineState{}
+
+			if err = json.Unmarshal(stateJson, state); err != nil {
+				t.Fatal(err)
+			}
+
+			e2, err := engine.LoadEngineState(gameData, state)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			loadedStateJson, _ := json.Marshal(engine.NewEngineState(e2))
+
+			if !bytes.Equal(stateJson, loadedStateJson) {
+				t.Fatal("loaded engine state does not match the saved state")
+			}
+
+			for !e1.GameOver || !e2.GameOver {
+				e1.Step()
+				e2.Step()
+			}
+
+			endStateJson, _ := json.Marshal(engine.NewEngineState(e1))
+			loadedEndStateJson, _ := json.Marshal(engine.NewEngineState(e2))
+
+			if !bytes.Equal(endStateJson, loadedEndStateJson) {
+				t.Error("continuing the loaded game gave a different result")
+			}
+		})
+	}
+}
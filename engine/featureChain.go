@@ -1,6 +1,10 @@
 package engine
 
-import "beeb/carcassonne/engine/tile"
+import (
+	"beeb/carcassonne/data"
+	"beeb/carcassonne/engine/board"
+	"beeb/carcassonne/engine/tile"
+)
 
 //a feature chain is an interlinked group of features,
 //like a big castle, or long road, or expansive farm
@@ -13,16 +17,31 @@ type FeatureChain struct {
 	playerMeeplesMap map[*Player][]*Meeple
 	owners           []*Player
 	score            int
+	gameBoard        *board.Board
+	rules            *data.RuleSet
 }
 
-func newFeatureChain(feature *tile.Feature) FeatureChain {
+func newFeatureChain(feature *tile.Feature, gameBoard *board.Board, rules *data.RuleSet) FeatureChain {
 	featureChain := FeatureChain{}
 	featureChain.Feature = feature
 	featureChain.FeaturesVisited = make(map[*tile.Feature]struct{})
 	featureChain.TilesVisited = make(map[*tile.Tile]struct{})
 
+	featureChain.gameBoard = gameBoard
+	featureChain.rules = rules
+
 	featureChain.traverseFeatureLinks(feature)
-	featureChain.isComplete = featureChain.determineCompleteness()
+
+	switch feature.Type {
+	case tile.Cloister, tile.Garden:
+		featureChain.traverseSurroundingTiles(gameBoard)
+		featureChain.isComplete = len(featureChain.TilesVisited) == 9
+	case tile.Farm:
+		//fields are never finished, they're only scored at the end of the game
+		featureChain.isComplete = false
+	default:
+		featureChain.isComplete = featureChain.determineCompleteness()
+	}
 
 	return featureChain
 }
@@ -47,6 +66,13 @@ func (fc *FeatureChain) traverseFeatureLinks(feature *tile.Feature) {
 	}
 }
 
+// a cloister or garden isn't linked to anything, instead it's made up of the tiles placed around it
+func (fc *FeatureChain) traverseSurroundingTiles(gameBoard *board.Board) {
+	for _, t := range gameBoard.SurroundingTiles(fc.Feature.ParentTile.Position) {
+		fc.TilesVisited[t] = struct{}{}
+	}
+}
+
 // if all the tiles' edges that are part of this feature are connected
 // to something, the feature must be complete, so for each edge the feature touches,
 // there must be a corresponding link
@@ -69,27 +95,128 @@ func (fc *FeatureChain) determineCompleteness() bool {
 }
 
 func (featureChain *FeatureChain) computeScore() {
+	featureChain.score = featureChain.scoreUsing(featureChain.rules.Score)
+}
+
+// computeEndGameScore
+// scores the chain as it's left at the end of the game, where unfinished features are worth less
+func (featureChain *FeatureChain) computeEndGameScore() {
+	if featureChain.isComplete {
+		featureChain.computeScore()
+		return
+	}
+
+	featureChain.score = featureChain.scoreUsing(featureChain.rules.EndGameScore)
+}
+
+func (featureChain *FeatureChain) scoreUsing(value func(tile.FeatureType) int) int {
 	f := featureChain.Feature
 	chainLenTiles := len(featureChain.TilesVisited)
 
 	switch f.Type {
 	case tile.Road:
-		featureChain.score = f.Type.Score() * chainLenTiles
-		return
+		//an inn on the road makes it worth more, or nothing if it's left unfinished
+		if featureChain.hasMarker(tile.Inn) {
+			return value(tile.Inn) * chainLenTiles
+		}
+
+		return value(f.Type) * chainLenTiles
+	case tile.Cloister, tile.Garden:
+		return value(f.Type) * chainLenTiles
 	case tile.Castle:
+		tileValue := value(f.Type)
+		shieldValue := value(tile.Shield)
+
+		//a cathedral makes the castle and its shields worth more, or nothing if it's left unfinished
+		if featureChain.hasMarker(tile.Cathedral) {
+			tileValue = value(tile.Cathedral)
+			shieldValue = value(tile.Cathedral)
+		}
+
 		//add base castle value
-		score := f.Type.Score() * chainLenTiles
+		score := tileValue * chainLenTiles
+
+		//add shields, they sit inside the castle so they're never linked to it,
+		//but they're next to the part of it they belong to, a shield in another castle on the same tile doesn't count
+		score += shieldValue * featureChain.countMarkers(tile.Shield)
 
-		//add shields
-		for vf := range featureChain.FeaturesVisited {
-			if vf.Type == tile.Shield {
-				score += f.Type.Score()
+		return score
+	case tile.Farm:
+		return value(f.Type) * featureChain.completedAdjacentCastles()
+	}
+
+	return 0
+}
+
+// hasMarker
+// whether any part of the chain touches a marker of the given type, like an inn beside a road.
+// markers are never linked to the chain, they're found next to it on its tiles
+func (featureChain *FeatureChain) hasMarker(markerType tile.FeatureType) bool {
+	for f := range featureChain.FeaturesVisited {
+		for _, rf := range f.ParentTile.Reference.AdjacentFeatures[f.ParentFeature] {
+			if rf.Type == markerType {
+				return true
 			}
 		}
+	}
 
-		featureChain.score = score
-		return
+	return false
+}
+
+// countMarkers
+// how many markers of the given type the chain touches, like the goods in a castle
+func (featureChain *FeatureChain) countMarkers(markerType tile.FeatureType) int {
+	type tileMarker struct {
+		t      *tile.Tile
+		marker *tile.Feature
+	}
+
+	markers := make(map[tileMarker]struct{})
+
+	for f := range featureChain.FeaturesVisited {
+		for _, rf := range f.ParentTile.Reference.AdjacentFeatures[f.ParentFeature] {
+			if rf.Type == markerType {
+				markers[tileMarker{t: f.ParentTile, marker: rf}] = struct{}{}
+			}
+		}
 	}
+
+	return len(markers)
+}
+
+// a field is worth something for each finished castle which borders it,
+// castles touching the field in more than one place only count once
+func (featureChain *FeatureChain) completedAdjacentCastles() int {
+	visitedCastleFeatures := make(map[*tile.Feature]struct{})
+	completedCastles := 0
+
+	for f := range featureChain.FeaturesVisited {
+		t := f.ParentTile
+
+		for _, rf := range t.Reference.AdjacentFeatures[f.ParentFeature] {
+			if rf.Type != tile.Castle {
+				continue
+			}
+
+			castleFeature := t.ReferenceFeatureMap[rf]
+
+			if _, exists := visitedCastleFeatures[castleFeature]; exists {
+				continue
+			}
+
+			castleChain := newFeatureChain(castleFeature, featureChain.gameBoard, featureChain.rules)
+
+			for cf := range castleChain.FeaturesVisited {
+				visitedCastleFeatures[cf] = struct{}{}
+			}
+
+			if castleChain.isComplete {
+				completedCastles++
+			}
+		}
+	}
+
+	return completedCastles
 }
 
 func (featureChain *FeatureChain) computeMeeples() {
@@ -115,25 +242,21 @@ func (featureChain *FeatureChain) computePlayerMeeplesMap() {
 	}
 }
 
+// computeOwners
+// the owners are the players with the most meeples on the chain, counted by their power,
+// so a big meeple counts as two
 func (featureChain *FeatureChain) computeOwners() {
 	playerMeeplesMap := featureChain.playerMeeplesMap
 
 	//get the most meeples on the feature, for any player
-	mostMeeplesOnFeature := 0
-	for _, meeples := range playerMeeplesMap {
-		numMeeples := len(meeples)
-
-		if numMeeples > mostMeeplesOnFeature {
-			mostMeeplesOnFeature = numMeeples
-		}
-	}
+	mostMeeplesOnFeature := featureChain.mostMeeplePower()
 
 	//the owners of the feature are the players with the most meeples
-	featureChain.owners = make([]*Player, 2)
+	featureChain.owners = make([]*Player, 0, 2)
 	for p, meeples := range playerMeeplesMap {
-		numMeeples := len(meeples)
+		numMeeples := meeplePower(meeples)
 
-		if numMeeples == mostMeeplesOnFeature {
+		if numMeeples == mostMeeplesOnFeature && numMeeples > 0 {
 			featureChain.owners = append(featureChain.owners, p)
 		}
 	}
@@ -156,17 +279,41 @@ func (featureChain *FeatureChain) potential() int {
 }
 
 func (featureChain *FeatureChain) distanceFromOwner(p *Player) int {
-	//get the most meeples on the feature, for any player
-	mostMeeplesOnFeature := 0
+	return featureChain.mostMeeplePower() - meeplePower(featureChain.playerMeeplesMap[p])
+}
+
+// the most meeple power on the chain, for any player
+func (featureChain *FeatureChain) mostMeeplePower() int {
+	mostMeeplePower := 0
 	for _, meeples := range featureChain.playerMeeplesMap {
-		numMeeples := len(meeples)
+		power := meeplePower(meeples)
+
+		if power > mostMeeplePower {
+			mostMeeplePower = power
+		}
+	}
+
+	return mostMeeplePower
+}
+
+func meeplePower(meeples []*Meeple) int {
+	power := 0
 
-		if numMeeples > mostMeeplesOnFeature {
-			mostMeeplesOnFeature = numMeeples
+	for _, m := range meeples {
+		power += m.Power
+	}
+
+	return power
+}
+
+func (featureChain *FeatureChain) hasFollowerOf(p *Player) bool {
+	for _, m := range featureChain.meeples {
+		if m.ParentPlayer == p && m.Type == Follower {
+			return true
 		}
 	}
 
-	return mostMeeplesOnFeature - len(featureChain.playerMeeplesMap[p])
+	return false
 }
 
 func (featureChain *FeatureChain) hasOwner() bool {
@@ -0,0 +1,54 @@
+package engine_test
+
+import (
+	"beeb/carcassonne/engine/tile"
+	"testing"
+)
+
+func TestEngine_ScoreField(t *testing.T) {
+	gameData := loadGameData(t, "../data/standard_deck.yml")
+
+	tests := []struct {
+		name     string
+		farmers  [2]int
+		expected [2]int
+	}{
+		{"tied", [2]int{1, 1}, [2]int{6, 6}},
+		{"majority", [2]int{2, 1}, [2]int{6, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEngine(t, gameData, 16, 2, 1)
+
+			//a field along the bottom of three castles, the first two are closed off by the tiles above them
+			field := []*tile.Tile{
+				placeTestTile(e, "CastleEndCap", 0, 4, 5),
+				placeTestTile(e, "CastleEndCap", 0, 5, 5),
+				placeTestTile(e, "CastleEndCap", 0, 6, 5),
+			}
+
+			placeTestTile(e, "CastleEndCap", 2, 4, 4)
+			placeTestTile(e, "CastleEndCap", 2, 5, 4)
+
+			for i, p := range e.Players {
+				for j := 0; j < tt.farmers[i]; j++ {
+					placeTestMeeple(p, field[i+j], tile.Farm)
+				}
+			}
+
+			scores := e.ScoreEndGame()
+
+			//only the finished castles count
+			if len(scores) != 1 || scores[0].Type != tile.Farm || scores[0].Score != 6 {
+				t.Fatalf("expected the field to be worth 6, got %+v", scores)
+			}
+
+			for i, p := range e.Players {
+				if p.Score != tt.expected[i] {
+					t.Errorf("player %d with %d farmers scored %d, want %d", i, tt.farmers[i], p.Score, tt.expected[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package engine
+
+import (
+	"beeb/carcassonne/engine/tile"
+	"beeb/carcassonne/engine/turnStage"
+)
+
+// playsHands
+// whether the players are holding hands of tiles, which they do once the river's been placed
+func (e *Engine) playsHands() bool {
+	return e.Rules.HandSize > 0 && e.RiverDeck.Remaining() == 0
+}
+
+// tilesInHand whether any of the players are still holding tiles
+func (e *Engine) tilesInHand() bool {
+	for _, p := range e.Players {
+		if len(p.hand) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// drawHand
+// tops the player's hand up from the deck, and works out where each of its tiles can go.
+// a hand with nowhere to go is shuffled back in and drawn again a few times, before a tile is thrown out.
+// once the deck's run out, the player plays out what's left in their hand, and passes when they've nothing left
+func (e *Engine) drawHand(player *Player) {
+	for i := 0; i < e.Rules.DrawAttempts; i++ {
+		for len(player.hand) < e.Rules.HandSize {
+			rtg, err := e.TakeNextTile()
+
+			if err != nil {
+				break
+			}
+
+			player.hand = append(player.hand, rtg)
+		}
+
+		if len(player.hand) == 0 {
+			e.TurnStage = turnStage.Pass
+			return
+		}
+
+		e.updatePossibleTilePlacements()
+
+		if len(e.CurrentPossibleTilePlacements) > 0 {
+			e.TurnStage++
+			return
+		}
+
+		for _, rtg := range player.hand {
+			e.Deck.Append(rtg)
+
+			e.emit(TileDiscardedEvent{
+				Turn:       e.TurnCounter,
+				Player:     player,
+				Tile:       rtg,
+				Reshuffled: true,
+			})
+		}
+
+		e.Deck.Shuffle(e.Rand)
+		player.hand = nil
+	}
+
+	//"nowhere to place tile, tried a few times, just remove tile completely" (take and do not place)
+	if rtg, err := e.TakeNextTile(); err == nil {
+		e.discardTile(player, rtg)
+	}
+}
+
+// handTilePlacements
+// where each of the tiles in the hand can go, a tile the player's holding more than once is only looked at once
+func (e *Engine) handTilePlacements(hand []*tile.ReferenceTileGroup) []Placement {
+	placements := make([]Placement, 0, 64)
+	seen := make(map[*tile.ReferenceTileGroup]struct{}, len(hand))
+
+	for _, rtg := range hand {
+		if _, exists := seen[rtg]; exists {
+			continue
+		}
+
+		seen[rtg] = struct{}{}
+
+		//the placement manager reuses its buffers, so the placements and their connections are copied out
+		for _, placement := range e.TilePlacementManager.PossibleTilePlacements(rtg) {
+			placement.ConnectedFeatures = append([]Connection(nil), placement.ConnectedFeatures...)
+			placements = append(placements, placement)
+		}
+	}
+
+	return placements
+}
@@ -0,0 +1,143 @@
+package engine_test
+
+import (
+	"beeb/carcassonne/data"
+	"beeb/carcassonne/engine"
+	"beeb/carcassonne/engine/turnStage"
+	"reflect"
+	"testing"
+)
+
+// handAI checks it's only offered the tiles in its own hand, then plays the first one
+type handAI struct {
+	t        *testing.T
+	handSize int
+}
+
+func (ai handAI) DeterminePlacement(o *engine.Observation, placementOptions []engine.Placement) (*engine.Placement, *engine.MeeplePlacement) {
+	hand := o.Hand()
+
+	if len(hand) < 1 || len(hand) > ai.handSize {
+		ai.t.Errorf("%s is holding %d tiles, expected 1 to %d", o.Player().Name, len(hand), ai.handSize)
+	}
+
+	for _, placement := range placementOptions {
+		held := false
+
+		for _, rtg := range hand {
+			if rtg.Name == placement.ReferenceTile.Name {
+				held = true
+				break
+			}
+		}
+
+		if !held {
+			ai.t.Errorf("%s was offered %s, which isn't in their hand", o.Player().Name, placement.ReferenceTile.Name)
+			break
+		}
+	}
+
+	if len(placementOptions) == 0 {
+		return nil, nil
+	}
+
+	return &placementOptions[0], nil
+}
+
+func TestEngine_HandOfTiles(t *testing.T) {
+	gameData := loadGameData(t, "../data/standard_deck.yml")
+
+	rules, err := data.LoadRuleSet("../data/hand_of_tiles_rules.yml")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gameData.Rules = rules
+	e1 := newEngine(t, gameData, 24, 3, 0)
+
+	var saved *engine.EngineState
+	discarded := 0
+
+	e1.Subscribe(engine.EventSubscriberFunc(func(e *engine.Engine, event engine.Event) {
+		if event, ok := event.(engine.TileDiscardedEvent); ok && !event.Reshuffled {
+			discarded++
+		}
+	}))
+
+	for !e1.GameOver {
+		//the river's played the usual way, the hands are dealt after it
+		if e1.RiverDeck.Remaining() == 0 && e1.TurnStage == turnStage.Draw {
+			for _, p := range e1.Players {
+				if _, isHandAI := p.AI.(handAI); !isHandAI {
+					p.AI = handAI{t: t, handSize: rules.HandSize}
+				}
+			}
+		}
+
+		if err := e1.Step(); err != nil {
+			t.Fatal(err)
+		}
+
+		if saved == nil && e1.TurnCounter == 40 && e1.TurnStage == turnStage.PlaceTile {
+			saved = engine.NewEngineState(e1)
+		}
+	}
+
+	//every tile in the game is played, including the ones left in the players' hands when the deck runs out
+	placed := e1.GameBoard.PlacedTileCount
+	total := 0
+
+	for _, count := range gameData.DeckInfo.Deck {
+		total += count
+	}
+
+	if placed+discarded != total {
+		t.Errorf("%d tiles placed and %d thrown out, out of %d in the game", placed, discarded, total)
+	}
+
+	for _, sp := range engine.NewEngineState(e1).Players {
+		if len(sp.Hand) > 0 {
+			t.Errorf("%s is still holding %v at the end of the game", sp.Name, sp.Hand)
+		}
+	}
+
+	//the hands are drawn the same way in a replay
+	e2, err := engine.Replay(gameData, e1.Record)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e2.GameBoard.PlacedTileCount != placed {
+		t.Errorf("%d tiles placed in the replay, expected %d", e2.GameBoard.PlacedTileCount, placed)
+	}
+
+	for i, p := range e1.Players {
+		if e2.Players[i].Score != p.Score {
+			t.Errorf("player %d scored %d in the replay, expected %d", i, e2.Players[i].Score, p.Score)
+		}
+	}
+
+	if saved == nil {
+		t.Fatal("the game ended before the state could be saved")
+	}
+
+	e3, err := engine.LoadEngineState(gameData, saved)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := engine.NewEngineState(e3)
+
+	for i, sp := range saved.Players {
+		if len(sp.Hand) < 1 || !reflect.DeepEqual(loaded.Players[i].Hand, sp.Hand) {
+			t.Errorf("player %d is holding %v after loading, expected %v", i, loaded.Players[i].Hand, sp.Hand)
+		}
+	}
+
+	if len(e3.CurrentPossibleTilePlacements) < 1 {
+		t.Error("loaded engine has nowhere to play the current player's hand")
+	}
+}
@@ -0,0 +1,309 @@
+package engine
+
+import (
+	"beeb/carcassonne/engine/tile"
+	"beeb/carcassonne/engine/turnStage"
+	"beeb/carcassonne/util"
+	"math/rand"
+)
+
+// turnHistory
+// everything a turn can change, as it was when the turn started, so the turn can be undone.
+// the board isn't copied, undoing the turn takes the placed tile off again
+type turnHistory struct {
+	turnCounter        int
+	currentPlayerIndex int
+
+	riverDeck  []*tile.ReferenceTileGroup
+	deck       []*tile.ReferenceTileGroup
+	randSource *countedSource
+	//tiles are only ever added to the discard pile, so it's enough to know how big it was
+	discardPile int
+
+	scores      []int
+	goods       []map[tile.FeatureType]int
+	attachments []meepleAttachment
+
+	towerHeights map[util.Point[int]]int
+	towerFloors  []int
+	prisoners    [][]*Meeple
+
+	hands [][]*tile.ReferenceTileGroup
+
+	isFirstRiverTurn bool
+	lastRiverTurn    int
+	lastRiverTile    *tile.Tile
+
+	builderTurn bool
+
+	dragonPosition *util.Point[int]
+	fairyPosition  *util.Point[int]
+	fairyMeeple    *Meeple
+
+	replayDraws       []string
+	replayDragonMoves []util.Point[int]
+	recordTurns       int
+
+	//nil until the player has decided, the tile is placed at the same time
+	action *Action
+	//where the dragon was moved to during the turn, so the turn can be redone the same way
+	dragonMoves []util.Point[int]
+}
+
+// redoTurn an undone turn, which can be played again
+type redoTurn struct {
+	action      Action
+	dragonMoves []util.Point[int]
+}
+
+type meepleAttachment struct {
+	feature *tile.Feature
+	meeples []interface{}
+}
+
+// beginTurnHistory
+// saves the state at the start of the turn. a turn can draw several times when tiles can't be placed,
+// only the first draw starts the turn. the oldest turn is forgotten once there are more than UndoLimit turns to undo
+func (e *Engine) beginTurnHistory() {
+	if e.UndoLimit < 1 {
+		return
+	}
+
+	if l := len(e.history); l > 0 && e.history[l-1].turnCounter == e.TurnCounter && e.history[l-1].action == nil {
+		return
+	}
+
+	th := turnHistory{
+		turnCounter:        e.TurnCounter,
+		currentPlayerIndex: e.CurrentPlayerIndex,
+		riverDeck:          copyTiles(e.RiverDeck.Tiles),
+		deck:               copyTiles(e.Deck.Tiles),
+		randSource:         e.randSource.clone(),
+		discardPile:        len(e.discardPile),
+		scores:             make([]int, len(e.Players)),
+		goods:              make([]map[tile.FeatureType]int, len(e.Players)),
+		towerHeights:       make(map[util.Point[int]]int, len(e.TowerHeights)),
+		towerFloors:        make([]int, len(e.Players)),
+		prisoners:          make([][]*Meeple, len(e.Players)),
+		hands:              make([][]*tile.ReferenceTileGroup, len(e.Players)),
+		isFirstRiverTurn:   e.isFirstRiverTurn,
+		lastRiverTurn:      e.lastRiverTurn,
+		lastRiverTile:      e.lastRiverTile,
+		builderTurn:        e.builderTurn,
+		dragonPosition:     e.DragonPosition,
+		fairyPosition:      e.FairyPosition,
+		fairyMeeple:        e.FairyMeeple,
+		replayDraws:        e.replayDraws,
+		replayDragonMoves:  e.replayDragonMoves,
+	}
+
+	if e.Record != nil {
+		th.recordTurns = len(e.Record.Turns)
+	}
+
+	for pos, height := range e.TowerHeights {
+		th.towerHeights[pos] = height
+	}
+
+	visitedFeatures := make(map[*tile.Feature]struct{})
+
+	for i, p := range e.Players {
+		th.scores[i] = p.Score
+		th.goods[i] = make(map[tile.FeatureType]int, len(p.Goods))
+
+		for goodsType, count := range p.Goods {
+			th.goods[i][goodsType] = count
+		}
+
+		th.towerFloors[i] = p.TowerFloors
+		th.prisoners[i] = make([]*Meeple, len(p.Prisoners))
+		copy(th.prisoners[i], p.Prisoners)
+
+		th.hands[i] = copyTiles(p.hand)
+
+		for _, m := range p.Meeples {
+			if m.Feature == nil {
+				continue
+			}
+
+			if _, exists := visitedFeatures[m.Feature]; exists {
+				continue
+			}
+
+			visitedFeatures[m.Feature] = struct{}{}
+
+			//keep the order of the attached meeples, so the redone turn plays out exactly the same
+			meeples := make([]interface{}, len(m.Feature.AttachedMeeples))
+			copy(meeples, m.Feature.AttachedMeeples)
+
+			th.attachments = append(th.attachments, meepleAttachment{
+				feature: m.Feature,
+				meeples: meeples,
+			})
+		}
+	}
+
+	//the turn in progress can't be undone yet, so it doesn't count towards the limit
+	if len(e.history) > e.UndoLimit {
+		copy(e.history, e.history[len(e.history)-e.UndoLimit:])
+		e.history = e.history[:e.UndoLimit]
+	}
+
+	e.history = append(e.history, th)
+}
+
+// Undo
+// takes back the last turn where a tile was placed, along with its meeple, the features it scored,
+// and the tile drawn, which goes back on the deck. anything done in the turn after it, like drawing the next tile,
+// is taken back too. the engine is then stepped up to the player's decision, as the tile will be drawn again.
+// only the last UndoLimit turns can be taken back
+func (e *Engine) Undo() error {
+	i := len(e.history) - 1
+
+	//the turn in progress hasn't placed anything yet
+	for i >= 0 && e.history[i].action == nil {
+		i--
+	}
+
+	if i < 0 {
+		return ErrNothingToUndo
+	}
+
+	th := e.history[i]
+	e.history = e.history[:i]
+
+	e.restoreTurnHistory(th)
+	e.redoTurns = append(e.redoTurns, redoTurn{
+		action:      *th.action,
+		dragonMoves: th.dragonMoves,
+	})
+
+	return e.StepUntilDecision()
+}
+
+// Redo
+// plays the last undone turn again. taking any other action clears the turns which can be redone
+func (e *Engine) Redo() error {
+	l := len(e.redoTurns)
+
+	if l < 1 {
+		return ErrNothingToRedo
+	}
+
+	rt := e.redoTurns[l-1]
+	redoTurns := e.redoTurns[:l-1]
+
+	if err := e.StepUntilDecision(); err != nil {
+		return err
+	}
+
+	//the dragon goes the same way again, unless it's already being replayed
+	if len(e.replayDragonMoves) < 1 {
+		e.replayDragonMoves = rt.dragonMoves
+	}
+
+	if err := e.Apply(rt.action); err != nil {
+		return err
+	}
+
+	e.redoTurns = redoTurns
+
+	return nil
+}
+
+func (e *Engine) restoreTurnHistory(th turnHistory) {
+	e.GameBoard.RemoveTileAt(th.action.Position)
+
+	for _, p := range e.Players {
+		for _, m := range p.Meeples {
+			m.Detach()
+			m.Captor = nil
+		}
+	}
+
+	for _, a := range th.attachments {
+		a.feature.AttachedMeeples = a.meeples
+
+		for _, m := range a.meeples {
+			m.(*Meeple).Feature = a.feature
+		}
+	}
+
+	for i, p := range e.Players {
+		p.Score = th.scores[i]
+		p.Goods = th.goods[i]
+		p.TowerFloors = th.towerFloors[i]
+		p.Prisoners = th.prisoners[i]
+		p.hand = th.hands[i]
+
+		for _, m := range p.Prisoners {
+			m.Captor = p
+		}
+	}
+
+	e.TowerHeights = th.towerHeights
+
+	e.RiverDeck.Tiles = th.riverDeck
+	e.Deck.Tiles = th.deck
+	e.discardPile = e.discardPile[:th.discardPile]
+
+	e.randSource = th.randSource
+	e.Rand = rand.New(e.randSource)
+
+	e.isFirstRiverTurn = th.isFirstRiverTurn
+	e.lastRiverTurn = th.lastRiverTurn
+	e.lastRiverTile = th.lastRiverTile
+
+	e.builderTurn = th.builderTurn
+	e.builderTriggered = false
+
+	e.DragonPosition = th.dragonPosition
+	e.FairyPosition = th.fairyPosition
+	e.FairyMeeple = th.fairyMeeple
+	e.dragonPath = nil
+
+	e.replayDraws = th.replayDraws
+	e.replayDragonMoves = th.replayDragonMoves
+
+	if e.Record != nil && len(e.Record.Turns) > th.recordTurns {
+		e.Record.Turns = e.Record.Turns[:th.recordTurns]
+	}
+
+	e.TurnCounter = th.turnCounter
+	e.CurrentPlayerIndex = th.currentPlayerIndex
+	e.TurnStage = turnStage.Draw
+	e.GameOver = false
+	e.EndGameScores = nil
+
+	e.TilePlacedThisTurn = nil
+	e.DecidedActionThisTurn = nil
+	e.DecidedMeeplePlacementThisTurn = nil
+	e.FeaturesScoredThisTurn = nil
+	e.MeeplePlacementError = nil
+	e.HeldRefTileGroup = nil
+	e.CurrentPossibleTilePlacements = nil
+	e.pendingAction = nil
+}
+
+// recordTurnAction
+// marks the turn as played, and clears the undone turns, they can't be redone once something else has been played
+func (e *Engine) recordTurnAction(action Action) {
+	if l := len(e.history); l > 0 {
+		e.history[l-1].action = &action
+	}
+
+	e.redoTurns = nil
+}
+
+func (e *Engine) recordTurnDragonMove(pos util.Point[int]) {
+	if l := len(e.history); l > 0 {
+		e.history[l-1].dragonMoves = append(e.history[l-1].dragonMoves, pos)
+	}
+}
+
+func copyTiles(tiles []*tile.ReferenceTileGroup) []*tile.ReferenceTileGroup {
+	tilesCopy := make([]*tile.ReferenceTileGroup, len(tiles))
+	copy(tilesCopy, tiles)
+
+	return tilesCopy
+}
@@ -0,0 +1,123 @@
+package engine_test
+
+import (
+	"beeb/carcassonne/engine"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestEngine_UndoRedo(t *testing.T) {
+	gameData := loadGameData(t, "../data/standard_deck.yml")
+	e := newEngine(t, gameData, 16, 4, 5)
+	e.UndoLimit = 100
+
+	if err := e.Undo(); !errors.Is(err, engine.ErrNothingToUndo) {
+		t.Errorf("Undo() before any turns, error = %v, want %v", err, engine.ErrNothingToUndo)
+	}
+
+	if err := e.StepUntilDecision(); err != nil {
+		t.Fatal(err)
+	}
+
+	stateJson := func() []byte {
+		b, _ := json.Marshal(engine.NewEngineState(e))
+		return b
+	}
+
+	//the state at each decision, with the final state last
+	states := make([][]byte, 0, 80)
+	//the open positions aren't part of the state, but they decide the legal actions
+	legalActionCounts := make([]int, 0, 80)
+
+	for !e.GameOver {
+		states = append(states, stateJson())
+
+		//prefer placing meeples, so meeples are returned and scored along the way
+		actions := e.LegalActions()
+		legalActionCounts = append(legalActionCounts, len(actions))
+
+		if err := e.Apply(actions[len(actions)-1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	states = append(states, stateJson())
+
+	for i := len(states) - 2; i >= 0; i-- {
+		if err := e.Undo(); err != nil {
+			t.Fatalf("Undo() back to turn %d, error = %v", i, err)
+		}
+
+		if !bytes.Equal(stateJson(), states[i]) {
+			t.Fatalf("undoing back to turn %d gave a different state", i)
+		}
+
+		if len(e.LegalActions()) != legalActionCounts[i] {
+			t.Fatalf("undoing back to turn %d gave different legal actions", i)
+		}
+	}
+
+	if err := e.Undo(); !errors.Is(err, engine.ErrNothingToUndo) {
+		t.Errorf("Undo() past the first turn, error = %v, want %v", err, engine.ErrNothingToUndo)
+	}
+
+	for i := 1; i < len(states); i++ {
+		if err := e.Redo(); err != nil {
+			t.Fatalf("Redo() up to turn %d, error = %v", i, err)
+		}
+
+		if !bytes.Equal(stateJson(), states[i]) {
+			t.Fatalf("redoing up to turn %d gave a different state", i)
+		}
+	}
+
+	if !e.GameOver {
+		t.Error("redoing every turn should end the game again")
+	}
+
+	//playing something new after an undo throws away the undone turns
+	if err := e.Undo(); err != nil {
+		t.Fatal(err)
+	}
+
+	actions := e.LegalActions()
+
+	if err := e.Apply(actions[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := e.Redo(); !errors.Is(err, engine.ErrNothingToRedo) {
+		t.Errorf("Redo() after a new action, error = %v, want %v", err, engine.ErrNothingToRedo)
+	}
+}
+
+func TestEngine_UndoLimit(t *testing.T) {
+	gameData := loadGameData(t, "../data/standard_deck.yml")
+
+	for _, limit := range []int{0, 3} {
+		e := newEngine(t, gameData, 16, 4, 5)
+		e.UndoLimit = limit
+
+		if err := e.StepUntilDecision(); err != nil {
+			t.Fatal(err)
+		}
+
+		for i := 0; i < 10; i++ {
+			if err := e.Apply(e.LegalActions()[0]); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		for i := 0; i < limit; i++ {
+			if err := e.Undo(); err != nil {
+				t.Fatalf("Undo() %d of %d, error = %v", i+1, limit, err)
+			}
+		}
+
+		if err := e.Undo(); !errors.Is(err, engine.ErrNothingToUndo) {
+			t.Errorf("Undo() past the limit of %d turns, error = %v, want %v", limit, err, engine.ErrNothingToUndo)
+		}
+	}
+}
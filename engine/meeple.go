@@ -6,9 +6,96 @@ import (
 	"github.com/google/uuid"
 )
 
+type MeepleType int
+
+var meepleTypeStrMap []string = []string{
+	"Follower",
+	"Builder",
+	"Pig",
+	"Abbot",
+}
+
+const (
+	//followers claim features, they're the only meeples which count when working out who owns a feature.
+	//a big meeple is a follower with a power of 2
+	Follower MeepleType = iota
+	//a builder joins a road or castle the player already has a follower on,
+	//and gives the player another turn when they extend it
+	Builder
+	//a pig joins a field the player already has a follower in, and makes it worth more to them
+	Pig
+	//an abbot claims a cloister or garden like a follower, and can be recalled from it before it's finished
+	Abbot
+)
+
+func (mt MeepleType) String() string {
+	return meepleTypeStrMap[mt]
+}
+
+// ParseMeepleType finds the meeple type with the given name, the opposite of String
+func ParseMeepleType(name string) (MeepleType, bool) {
+	for i, str := range meepleTypeStrMap {
+		if str == name {
+			return MeepleType(i), true
+		}
+	}
+
+	return Follower, false
+}
+
+// CanJoin
+// whether this type of meeple can be placed on this type of feature
+func (mt MeepleType) CanJoin(ft tile.FeatureType) bool {
+	switch mt {
+	case Builder:
+		return ft == tile.Road || ft == tile.Castle
+	case Pig:
+		return ft == tile.Farm
+	case Abbot:
+		return ft == tile.Cloister || ft == tile.Garden
+	}
+
+	return ft.Claimable()
+}
+
+// Claims
+// whether this type of meeple claims the feature it's placed on, rather than joining one of the player's followers already there
+func (mt MeepleType) Claims() bool {
+	return mt == Follower || mt == Abbot
+}
+
 type Meeple struct {
 	Id           uuid.UUID
+	Type         MeepleType
 	Power        int
 	ParentPlayer *Player
 	Feature      *tile.Feature
+
+	//the player holding the meeple prisoner, after capturing it with a tower. it can't be placed until it's ransomed
+	Captor *Player
+}
+
+// inSupply whether the meeple can be placed, it's not on the board or held prisoner
+func (m *Meeple) inSupply() bool {
+	return m.Feature == nil && m.Captor == nil
+}
+
+// Detach removes the meeple from the feature it's sitting on, which returns it to the player's pool
+func (m *Meeple) Detach() {
+	if m.Feature == nil {
+		return
+	}
+
+	//fast-remove meeple from feature list
+	for i, am := range m.Feature.AttachedMeeples {
+		if am == m {
+			l := len(m.Feature.AttachedMeeples) - 1
+			m.Feature.AttachedMeeples[i] = m.Feature.AttachedMeeples[l]
+			m.Feature.AttachedMeeples = m.Feature.AttachedMeeples[:l]
+			break
+		}
+	}
+
+	//setting the meeples feature to nil returns it to the pool
+	m.Feature = nil
 }
@@ -0,0 +1,390 @@
+package engine
+
+import (
+	"beeb/carcassonne/data"
+	"beeb/carcassonne/engine/board"
+	"beeb/carcassonne/engine/deck"
+	"beeb/carcassonne/engine/tile"
+	"beeb/carcassonne/engine/turnStage"
+	"beeb/carcassonne/util"
+	"image/color"
+	"math/rand"
+
+	"github.com/google/uuid"
+)
+
+// Observation
+// the game as one player sees it when they're deciding on their turn, it's all their AI is given.
+// the engine behind it isn't exported, so an AI outside the engine can't look at the other players' hands or the order of the deck,
+// and what it hands out are copies, so the AI can't change the game by accident. the meeples in it are copies from the board,
+// the engine finds its own meeples by their ids when the AI decides on one. the reference tiles of each orientation are the only thing
+// it shares with the game, they're the game data's, which nothing changes.
+// it's only good for the decision it was made for, the game moves on once the AI has decided
+type Observation struct {
+	engine *Engine
+	player *Player
+
+	//a copy of the game for the board and meeples to come from, made the first time it's asked for
+	snapshot *Engine
+	meeples  map[uuid.UUID]*Meeple
+
+	rand *rand.Rand
+}
+
+// ObservedPlayer
+// what everyone at the table can see of a player, their score and what they have left in their supply.
+// the id is the same as their meeples' ParentPlayer on the observed board
+type ObservedPlayer struct {
+	Id    uuid.UUID
+	Index int
+	Name  string
+	Color color.Color
+	Score int
+
+	//the meeples the player has left to place, by type, and by power for their followers
+	Meeples        map[MeepleType]int
+	FollowerPowers map[int]int
+	//every follower the player has, on the board or not
+	Followers int
+
+	Goods       map[tile.FeatureType]int
+	TowerFloors int
+	//how many of the other players' meeples they're holding prisoner
+	Prisoners int
+	//how many tiles they're holding, but not which ones
+	TilesInHand int
+}
+
+// Observe the game as the player sees it
+func (e *Engine) Observe(p *Player) *Observation {
+	return &Observation{
+		engine: e,
+		player: p,
+	}
+}
+
+// Player the player making the observation
+func (o *Observation) Player() ObservedPlayer {
+	for i, p := range o.engine.Players {
+		if p == o.player {
+			return observePlayer(i, p)
+		}
+	}
+
+	return observePlayer(-1, o.player)
+}
+
+// Players every player in turn order, including the one making the observation
+func (o *Observation) Players() []ObservedPlayer {
+	players := make([]ObservedPlayer, len(o.engine.Players))
+
+	for i, p := range o.engine.Players {
+		players[i] = observePlayer(i, p)
+	}
+
+	return players
+}
+
+func observePlayer(index int, p *Player) ObservedPlayer {
+	op := ObservedPlayer{
+		Id:             p.Id,
+		Index:          index,
+		Name:           p.Name,
+		Color:          p.Color,
+		Score:          p.Score,
+		Meeples:        make(map[MeepleType]int, 4),
+		FollowerPowers: make(map[int]int, 2),
+		Goods:          make(map[tile.FeatureType]int, len(p.Goods)),
+		TowerFloors:    p.TowerFloors,
+		Prisoners:      len(p.Prisoners),
+		TilesInHand:    len(p.hand),
+	}
+
+	for _, m := range p.Meeples {
+		if m.Type == Follower {
+			op.Followers++
+		}
+
+		if !m.inSupply() {
+			continue
+		}
+
+		op.Meeples[m.Type]++
+
+		if m.Type == Follower {
+			op.FollowerPowers[m.Power]++
+		}
+	}
+
+	for goodsType, count := range p.Goods {
+		op.Goods[goodsType] = count
+	}
+
+	return op
+}
+
+// Turn the turn counter, which goes up by one each turn
+func (o *Observation) Turn() int {
+	return o.engine.TurnCounter
+}
+
+// Rules a copy of the rules the game's played by
+func (o *Observation) Rules() data.RuleSet {
+	rules := *o.engine.Rules
+
+	rules.Scores = copyScores(o.engine.Rules.Scores)
+	rules.EndGameScores = copyScores(o.engine.Rules.EndGameScores)
+
+	return rules
+}
+
+func copyScores(scores map[string]int) map[string]int {
+	scoresCopy := make(map[string]int, len(scores))
+
+	for name, score := range scores {
+		scoresCopy[name] = score
+	}
+
+	return scoresCopy
+}
+
+// Board
+// a copy of the board, with copies of the players' meeples on it. it's only copied once for the observation,
+// so anything done to it is seen the next time it's asked for
+func (o *Observation) Board() *board.Board {
+	return o.snapshotEngine().GameBoard
+}
+
+func (o *Observation) snapshotEngine() *Engine {
+	if o.snapshot == nil {
+		o.snapshot = o.engine.Clone()
+	}
+
+	return o.snapshot
+}
+
+// observedMeeple the copy of the meeple on the observed board, or in its player's supply
+func (o *Observation) observedMeeple(m *Meeple) *Meeple {
+	if m == nil {
+		return nil
+	}
+
+	if o.meeples == nil {
+		o.meeples = make(map[uuid.UUID]*Meeple)
+
+		for _, p := range o.snapshotEngine().Players {
+			for _, pm := range p.Meeples {
+				o.meeples[pm.Id] = pm
+			}
+		}
+	}
+
+	return o.meeples[m.Id]
+}
+
+// Meeples
+// copies of the player's own meeples, on the board or not. a meeple the AI decides to place has to be one of these,
+// the engine places its own meeple with the same id
+func (o *Observation) Meeples() []*Meeple {
+	for _, p := range o.snapshotEngine().Players {
+		if p.Id == o.player.Id {
+			meeples := make([]*Meeple, len(p.Meeples))
+			copy(meeples, p.Meeples)
+
+			return meeples
+		}
+	}
+
+	return nil
+}
+
+// FairyMeeple the copy of the follower the fairy's next to, nil when she's not with anyone
+func (o *Observation) FairyMeeple() *Meeple {
+	return o.observedMeeple(o.engine.FairyMeeple)
+}
+
+// OpenPositions the empty positions next to the tiles on the board, where a tile could go
+func (o *Observation) OpenPositions() []util.Point[int] {
+	positions := o.engine.GameBoard.OpenPositionsList()
+	positionsCopy := make([]util.Point[int], len(positions))
+	copy(positionsCopy, positions)
+
+	return positionsCopy
+}
+
+// DragonPosition where the dragon is, nil when it isn't on the board yet
+func (o *Observation) DragonPosition() *util.Point[int] {
+	return copyPosition(o.engine.DragonPosition)
+}
+
+// FairyPosition where the fairy is, nil when she hasn't been moved yet
+func (o *Observation) FairyPosition() *util.Point[int] {
+	return copyPosition(o.engine.FairyPosition)
+}
+
+func copyPosition(pos *util.Point[int]) *util.Point[int] {
+	if pos == nil {
+		return nil
+	}
+
+	posCopy := *pos
+
+	return &posCopy
+}
+
+// TowerHeights the towers on the board by position, and how many floors have been built on each
+func (o *Observation) TowerHeights() map[util.Point[int]]int {
+	heights := make(map[util.Point[int]]int, len(o.engine.TowerHeights))
+
+	for pos, height := range o.engine.TowerHeights {
+		heights[pos] = height
+	}
+
+	return heights
+}
+
+// HeldTile
+// a copy of the tile the player drew this turn, nil when they're picking from their hand.
+// its orientations are the game data's reference tiles, like those in the placements the AI picks from
+func (o *Observation) HeldTile() *tile.ReferenceTileGroup {
+	return copyTileGroup(o.engine.HeldRefTileGroup)
+}
+
+// Hand
+// copies of the tiles in the player's hand, when the game's played with hands, so changing them doesn't change the hand
+func (o *Observation) Hand() []*tile.ReferenceTileGroup {
+	hand := make([]*tile.ReferenceTileGroup, len(o.player.hand))
+
+	for i, rtg := range o.player.hand {
+		hand[i] = copyTileGroup(rtg)
+	}
+
+	return hand
+}
+
+func copyTileGroup(rtg *tile.ReferenceTileGroup) *tile.ReferenceTileGroup {
+	if rtg == nil {
+		return nil
+	}
+
+	rtgCopy := *rtg
+
+	rtgCopy.Features = make([]*tile.Feature, len(rtg.Features))
+	copy(rtgCopy.Features, rtg.Features)
+
+	rtgCopy.Orientations = make([]*tile.ReferenceTile, len(rtg.Orientations))
+	copy(rtgCopy.Orientations, rtg.Orientations)
+
+	return &rtgCopy
+}
+
+// copyPlacements
+// a copy of the placements an AI is offered, so reordering or changing them doesn't change where the engine lets the tile go
+func copyPlacements(placements []Placement) []Placement {
+	placementsCopy := make([]Placement, len(placements))
+
+	for i, placement := range placements {
+		placementsCopy[i] = placement
+		placementsCopy[i].ConnectedFeatures = make([]Connection, len(placement.ConnectedFeatures))
+		copy(placementsCopy[i].ConnectedFeatures, placement.ConnectedFeatures)
+	}
+
+	return placementsCopy
+}
+
+// TilesRemaining how many tiles are left to be drawn, in the river and the main deck
+func (o *Observation) TilesRemaining() int {
+	return o.engine.RiverDeck.Remaining() + o.engine.Deck.Remaining()
+}
+
+// UnseenTiles
+// how many of each tile the player hasn't seen yet, by name. that's the tiles left to be drawn,
+// and those in the other players' hands, which they can't tell apart. the order they'll be drawn in isn't given away
+func (o *Observation) UnseenTiles() map[string]int {
+	counts := make(map[string]int, len(o.engine.GameData.DeckInfo.Deck))
+
+	for _, d := range []*deck.Deck{o.engine.RiverDeck, o.engine.Deck} {
+		for _, rtg := range d.Tiles {
+			counts[rtg.Name]++
+		}
+	}
+
+	for _, p := range o.engine.Players {
+		if p == o.player {
+			continue
+		}
+
+		for _, rtg := range p.hand {
+			counts[rtg.Name]++
+		}
+	}
+
+	return counts
+}
+
+// ReferenceTileGroup the game data's tile with the name, like one counted by UnseenTiles, nil when there's no such tile
+func (o *Observation) ReferenceTileGroup(name string) *tile.ReferenceTileGroup {
+	return o.engine.GameData.ReferenceTileGroups[name]
+}
+
+// RemainingTiles how many of each tile are still to be played, see Engine.RemainingTiles
+func (o *Observation) RemainingTiles() map[string]int {
+	return o.engine.RemainingTiles()
+}
+
+// Fillability how likely each open position is to be filled, see Engine.Fillability
+func (o *Observation) Fillability() []Fillability {
+	return o.engine.Fillability()
+}
+
+// LegalActions
+// every action the player can take this turn, see Engine.LegalActions.
+// the meeples the actions send home, move the fairy to, recall or capture are the copies on the observed board
+func (o *Observation) LegalActions() []Action {
+	if o.engine.CurrentPlayer() != o.player {
+		return nil
+	}
+
+	return o.observedActions(o.engine.LegalActions())
+}
+
+// LegalPlacementActions the player's legal actions which put the tile down with the placement, see LegalActions
+func (o *Observation) LegalPlacementActions(placement Placement) []Action {
+	if o.engine.CurrentPlayer() != o.player {
+		return nil
+	}
+
+	return o.observedActions(o.engine.LegalPlacementActions(placement))
+}
+
+func (o *Observation) observedActions(actions []Action) []Action {
+	for i := range actions {
+		actions[i].PrincessMeeple = o.observedMeeple(actions[i].PrincessMeeple)
+		actions[i].FairyMeeple = o.observedMeeple(actions[i].FairyMeeple)
+		actions[i].RecalledAbbot = o.observedMeeple(actions[i].RecalledAbbot)
+		actions[i].CapturedMeeple = o.observedMeeple(actions[i].CapturedMeeple)
+	}
+
+	return actions
+}
+
+// DragonMoves where the dragon can move next, while it's being moved
+func (o *Observation) DragonMoves() []util.Point[int] {
+	if o.engine.TurnStage != turnStage.MoveDragon {
+		return nil
+	}
+
+	return o.engine.DragonMoves()
+}
+
+// Rand
+// a random source for the AI to decide with, seeded from a hash of the game's seed, the turn and the player,
+// so the same game plays out the same way. AIs used to draw from the engine's own Rand, so what an AI drew
+// changed how the deck was reshuffled later on. this source is the AI's alone, drawing from it doesn't change the game
+func (o *Observation) Rand() *rand.Rand {
+	if o.rand == nil {
+		o.rand = rand.New(rand.NewSource(aiSeed(o.engine.Seed, o.engine.TurnCounter, o.Player().Index)))
+	}
+
+	return o.rand
+}
@@ -0,0 +1,190 @@
+package engine_test
+
+import (
+	"beeb/carcassonne/data"
+	"beeb/carcassonne/engine"
+	"beeb/carcassonne/util"
+	"testing"
+)
+
+// observingAI
+// checks what it's given matches the game, and that changing it doesn't change the game,
+// then plays the first legal action with one of the meeples it's handed, or which places a meeple, or the first placement
+type observingAI struct {
+	t      *testing.T
+	e      *engine.Engine
+	player *engine.Player
+
+	decisions   *int
+	dragonMoves *int
+	//decisions taken with a meeple from a legal action, which the engine has to find its own meeple for
+	meepleActions *int
+}
+
+// gameMeeple the game's own meeple with the id, nil when there isn't one
+func (ai observingAI) gameMeeple(m *engine.Meeple) *engine.Meeple {
+	for _, p := range ai.e.Players {
+		for _, pm := range p.Meeples {
+			if pm.Id == m.Id {
+				return pm
+			}
+		}
+	}
+
+	return nil
+}
+
+func (ai observingAI) DeterminePlacement(o *engine.Observation, placementOptions []engine.Placement) (*engine.Placement, *engine.MeeplePlacement) {
+	*ai.decisions++
+
+	me := o.Player()
+
+	if me.Name != ai.player.Name || o.Players()[me.Index].Score != ai.player.Score {
+		ai.t.Errorf("turn %d: observed as %s with %d points, expected %s with %d", o.Turn(), me.Name, me.Score, ai.player.Name, ai.player.Score)
+	}
+
+	if held := o.HeldTile(); held == nil || held == ai.e.HeldRefTileGroup || held.Name != ai.e.HeldRefTileGroup.Name {
+		ai.t.Errorf("turn %d: observed holding %v, expected a copy of %v", o.Turn(), held, ai.e.HeldRefTileGroup)
+	}
+
+	if len(o.LegalActions()) < len(placementOptions) {
+		ai.t.Errorf("turn %d: %d legal actions for %d placements", o.Turn(), len(o.LegalActions()), len(placementOptions))
+	}
+
+	unseen := 0
+	for _, count := range o.UnseenTiles() {
+		unseen += count
+	}
+
+	if unseen != o.TilesRemaining() {
+		ai.t.Errorf("turn %d: %d tiles unseen, expected the %d left to draw", o.Turn(), unseen, o.TilesRemaining())
+	}
+
+	//nothing the AI does to what it's given changes the game
+	placed := ai.e.GameBoard.PlacedTileCount
+
+	for _, t := range o.Board().PlacedTiles() {
+		o.Board().RemoveTileAt(t.Position)
+	}
+
+	o.Rules().Scores["Castle"] = 100
+	me.Meeples[engine.Follower] = 100
+	o.HeldTile().Orientations[0] = nil
+
+	options := make([]engine.Placement, len(placementOptions))
+	copy(options, placementOptions)
+
+	for i := range placementOptions {
+		placementOptions[i] = engine.Placement{}
+	}
+
+	if ai.e.GameBoard.PlacedTileCount != placed || ai.e.Rules.Scores["Castle"] == 100 || o.Player().Meeples[engine.Follower] == 100 ||
+		ai.e.HeldRefTileGroup.Orientations[0] == nil || ai.e.CurrentPossibleTilePlacements[0].ReferenceTile == nil {
+		ai.t.Errorf("turn %d: changing the observation changed the game", o.Turn())
+	}
+
+	placementOptions = options
+
+	//the meeples in the legal actions are copies, and the engine maps them back to its own by id when one is decided on
+	for _, a := range o.LegalActions() {
+		for _, m := range []*engine.Meeple{a.PrincessMeeple, a.FairyMeeple, a.RecalledAbbot, a.CapturedMeeple} {
+			if m == nil {
+				continue
+			}
+
+			gm := ai.gameMeeple(m)
+
+			if gm == nil || gm == m {
+				ai.t.Fatalf("turn %d: legal action handed out the game's meeple, or one which isn't in the game", o.Turn())
+			}
+
+			feature, power := gm.Feature, gm.Power
+			m.Feature = nil
+			m.Power = 100
+
+			if gm.Feature != feature || gm.Power != power {
+				ai.t.Errorf("turn %d: changing a legal action's meeple changed the game's", o.Turn())
+			}
+
+			*ai.meepleActions++
+			placement := a.Placement()
+
+			return &placement, &engine.MeeplePlacement{
+				PrincessMeeple: a.PrincessMeeple,
+				FairyMeeple:    a.FairyMeeple,
+				RecalledAbbot:  a.RecalledAbbot,
+				TowerPosition:  a.TowerPosition,
+				CapturedMeeple: a.CapturedMeeple,
+			}
+		}
+	}
+
+	for _, a := range o.LegalActions() {
+		if !a.PlacesMeeple() {
+			continue
+		}
+
+		for _, m := range o.Meeples() {
+			if m.Feature == nil && m.Captor == nil && m.Type == a.MeepleType && m.Power == a.MeeplePower {
+				placement := a.Placement()
+
+				return &placement, &engine.MeeplePlacement{
+					SelectedMeeple: m,
+					ParentFeature:  a.MeepleFeature,
+					PortalPosition: a.PortalPosition,
+				}
+			}
+		}
+	}
+
+	if len(placementOptions) == 0 {
+		return nil, nil
+	}
+
+	return &placementOptions[0], nil
+}
+
+func (ai observingAI) DetermineDragonMove(o *engine.Observation, moves []util.Point[int]) util.Point[int] {
+	*ai.dragonMoves++
+
+	if len(o.DragonMoves()) != len(moves) {
+		ai.t.Errorf("turn %d: dragon observed with %d moves, expected %d", o.Turn(), len(o.DragonMoves()), len(moves))
+	}
+
+	return moves[len(moves)-1]
+}
+
+func TestEngine_Observation(t *testing.T) {
+	gameData := loadGameData(t, "../data/princess_and_dragon_deck.yml")
+
+	rules, err := data.LoadRuleSet("../data/princess_and_dragon_rules.yml")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gameData.Rules = rules
+	e := newEngine(t, gameData, 24, 3, 4)
+
+	decisions := 0
+	dragonMoves := 0
+	meepleActions := 0
+
+	for _, p := range e.Players {
+		p.AI = observingAI{t: t, e: e, player: p, decisions: &decisions, dragonMoves: &dragonMoves, meepleActions: &meepleActions}
+	}
+
+	for !e.GameOver {
+		if err := e.Step(); err != nil {
+			t.Fatal(err)
+		}
+
+		if e.MeeplePlacementError != nil {
+			t.Fatalf("legal action with a copied meeple wasn't taken: %v", e.MeeplePlacementError)
+		}
+	}
+
+	if decisions == 0 || dragonMoves == 0 || meepleActions == 0 {
+		t.Errorf("AIs decided %d tile placements, %d dragon moves and %d meeple actions, expected all of them", decisions, dragonMoves, meepleActions)
+	}
+}
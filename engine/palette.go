@@ -0,0 +1,78 @@
+package engine
+
+import (
+	"image/color"
+	"math"
+
+	"golang.org/x/exp/shiny/materialdesign/colornames"
+)
+
+// classicPlayerColors the colours the first players get, the palette carries on from them
+var classicPlayerColors = [...]color.RGBA{
+	colornames.White,
+	colornames.Red500,
+	colornames.Blue500,
+	colornames.Green500,
+	colornames.Black,
+}
+
+// hues are spread around the colour wheel by the golden angle, so each new colour is as far as it can be from the ones before it.
+// the first is orange, between the classic red and green
+const (
+	paletteStartHue   = 30.0
+	paletteGoldenStep = 137.508
+)
+
+// PlayerColors
+// a colour for each of n players. the classic colours come first, then as many generated colours as it takes
+func PlayerColors(n int) []color.RGBA {
+	colors := make([]color.RGBA, n)
+
+	for i := range colors {
+		if i < len(classicPlayerColors) {
+			colors[i] = classicPlayerColors[i]
+			continue
+		}
+
+		generated := i - len(classicPlayerColors)
+		hue := math.Mod(paletteStartHue+float64(generated)*paletteGoldenStep, 360)
+
+		//each time round the wheel is a little darker, so hues which come round close together still look different
+		value := 0.9 - 0.2*float64((generated/5)%3)
+
+		colors[i] = hsvColor(hue, 0.75, value)
+	}
+
+	return colors
+}
+
+// hsvColor an opaque colour from a hue in degrees, and a saturation and value between 0 and 1
+func hsvColor(hue float64, saturation float64, value float64) color.RGBA {
+	chroma := value * saturation
+	x := chroma * (1 - math.Abs(math.Mod(hue/60, 2)-1))
+	m := value - chroma
+
+	var r, g, b float64
+
+	switch {
+	case hue < 60:
+		r, g, b = chroma, x, 0
+	case hue < 120:
+		r, g, b = x, chroma, 0
+	case hue < 180:
+		r, g, b = 0, chroma, x
+	case hue < 240:
+		r, g, b = 0, x, chroma
+	case hue < 300:
+		r, g, b = x, 0, chroma
+	default:
+		r, g, b = chroma, 0, x
+	}
+
+	return color.RGBA{
+		R: uint8(math.Round((r + m) * 255)),
+		G: uint8(math.Round((g + m) * 255)),
+		B: uint8(math.Round((b + m) * 255)),
+		A: 255,
+	}
+}
@@ -119,11 +119,7 @@ func (tpa *TilePlacementAgent) getPlaceableOrientations(openPosKey util.Point[in
 			for edge, feature := range rt.EdgeFeatures {
 				edgeDir := directions.Direction(edge)
 				neighbourPos := openPosKey.EdgePos(edgeDir)
-				otherTile, err := e.GameBoard.TileMatrix.GetPt(neighbourPos)
-
-				if err != nil {
-					continue
-				}
+				otherTile := e.GameBoard.Get(neighbourPos)
 
 				complimentDir := directions.Compliment[edgeDir]
 
@@ -146,8 +142,23 @@ func (tpm *TilePlacementManager) firstTilePlacement(rtg *tile.ReferenceTileGroup
 	e := tpm.engine
 	placements := make([]Placement, 0, len(e.GameBoard.OpenPositions))
 
-	middle := e.GameBoard.TileMatrix.Size() / 2
-	quarter := e.GameBoard.TileMatrix.Size() / 4
+	//there are no quadrants on a board without edges, so start in the middle facing any direction
+	if !e.GameBoard.Bounded() || !e.Rules.QuadrantStart {
+		for d := range rtg.Orientations {
+			placements = append(placements,
+				Placement{
+					Position:          e.GameBoard.Center(),
+					ReferenceTile:     rtg.Orientations[d],
+					ConnectedFeatures: make([]Connection, 0),
+				},
+			)
+		}
+
+		return placements
+	}
+
+	middle := e.GameBoard.Size / 2
+	quarter := e.GameBoard.Size / 4
 	// for each quadrant to start it
 	for i := 0; i < 4; i++ {
 		top := i < 2     //true, true, false, false
@@ -186,11 +197,11 @@ func (tpm *TilePlacementManager) firstTilePlacement(rtg *tile.ReferenceTileGroup
 	return placements
 }
 
-func RandomPlacement(placements []Placement) *Placement {
+func RandomPlacement(r *rand.Rand, placements []Placement) *Placement {
 	if len(placements) == 0 {
 		return nil
 	}
 
-	randN := rand.Int() % len(placements)
+	randN := r.Int() % len(placements)
 	return &placements[randN]
 }
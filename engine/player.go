@@ -1,8 +1,10 @@
 package engine
 
 import (
+	"beeb/carcassonne/engine/tile"
 	"github.com/google/uuid"
 	"image/color"
+	"sort"
 )
 
 type Player struct {
@@ -12,41 +14,70 @@ type Player struct {
 	Score   int
 	Meeples []*Meeple
 
+	//the goods collected from the castles the player has finished, by type
+	Goods map[tile.FeatureType]int
+
+	//the tower floors the player has left to build, and the other players' meeples they've captured with towers
+	TowerFloors int
+	Prisoners   []*Meeple
+
+	//the tiles the player's holding, when the game's played with hands. no one else can see them,
+	//the player's AI only gets them through its observation of the game
+	hand []*tile.ReferenceTileGroup
+
 	AI PlayerAI
 }
 
-var MaxMeeples int = 7
-
-func NewPlayer(name string, color color.Color) *Player {
+func NewPlayer(name string, color color.Color, meeples int) *Player {
 	player := &Player{}
 
 	player.Id = uuid.New()
 	player.Name = name
-	player.Meeples = make([]*Meeple, MaxMeeples)
+	player.Meeples = make([]*Meeple, 0, meeples)
 	player.Color = color
+	player.Goods = make(map[tile.FeatureType]int)
 
-	for i := 0; i < MaxMeeples; i++ {
-		player.Meeples[i] = &Meeple{
-			Id:           uuid.New(),
-			ParentPlayer: player,
-			Power:        1,
-			Feature:      nil,
-		}
-	}
+	player.AddMeeples(meeples, Follower, 1)
 
 	player.AI = &BasicPlayerAI{
-		Player:     player,
 		Evaluation: Evaluation{},
 	}
 
 	return player
 }
 
+// AddMeeples
+// gives the player more meeples, like a big meeple, which is a follower with a power of 2.
+// builders and pigs never count towards owning a feature, so their power should be 0
+func (p *Player) AddMeeples(count int, meepleType MeepleType, power int) {
+	for i := 0; i < count; i++ {
+		p.Meeples = append(p.Meeples, &Meeple{
+			Id:           uuid.New(),
+			Type:         meepleType,
+			ParentPlayer: p,
+			Power:        power,
+			Feature:      nil,
+		})
+	}
+}
+
+func (p *Player) numFollowers() int {
+	c := 0
+
+	for _, m := range p.Meeples {
+		if m.Type == Follower {
+			c++
+		}
+	}
+
+	return c
+}
+
 func (p *Player) numRemainingMeeples() int {
 	c := 0
 
 	for _, m := range p.Meeples {
-		if m.Feature == nil {
+		if m.inSupply() && m.Type == Follower {
 			c++
 		}
 	}
@@ -54,9 +85,26 @@ func (p *Player) numRemainingMeeples() int {
 	return c
 }
 
+// GetAvailableMeepleWithPower gets a follower with the given power which hasn't been placed
 func (p *Player) GetAvailableMeepleWithPower(power int) *Meeple {
 	for _, m := range p.Meeples {
-		if m.Feature == nil && m.Power == power {
+		if m.inSupply() && m.Type == Follower && m.Power == power {
+			return m
+		}
+	}
+
+	return nil
+}
+
+// GetAvailableMeeple gets any follower which hasn't been placed, no matter its power
+func (p *Player) GetAvailableMeeple() *Meeple {
+	return p.GetAvailableMeepleOfType(Follower)
+}
+
+// GetAvailableMeepleOfType gets a meeple of the given type which hasn't been placed
+func (p *Player) GetAvailableMeepleOfType(meepleType MeepleType) *Meeple {
+	for _, m := range p.Meeples {
+		if m.inSupply() && m.Type == meepleType {
 			return m
 		}
 	}
@@ -64,6 +112,78 @@ func (p *Player) GetAvailableMeepleWithPower(power int) *Meeple {
 	return nil
 }
 
+// availableMeepleKinds
+// one of each kind of meeple the player has left to place, followers of each power first, in ascending order,
+// then the special meeples by type
+func (p *Player) availableMeepleKinds() []*Meeple {
+	kinds := make([]*Meeple, 0, 2)
+
+	for _, m := range p.Meeples {
+		if !m.inSupply() {
+			continue
+		}
+
+		exists := false
+		for _, km := range kinds {
+			if km.Type == m.Type && km.Power == m.Power {
+				exists = true
+				break
+			}
+		}
+
+		if !exists {
+			kinds = append(kinds, m)
+		}
+	}
+
+	sort.SliceStable(kinds, func(i, j int) bool {
+		if kinds[i].Type != kinds[j].Type {
+			return kinds[i].Type < kinds[j].Type
+		}
+
+		return kinds[i].Power < kinds[j].Power
+	})
+
+	return kinds
+}
+
+// releasePrisoner hands a meeple the player captured back to its owner
+func (p *Player) releasePrisoner(m *Meeple) {
+	prisoners := make([]*Meeple, 0, len(p.Prisoners))
+
+	for _, pm := range p.Prisoners {
+		if pm != m {
+			prisoners = append(prisoners, pm)
+		}
+	}
+
+	p.Prisoners = prisoners
+	m.Captor = nil
+}
+
+// placedMeepleOfType gets a meeple of the given type which is on the board, like the player's builder
+func (p *Player) placedMeepleOfType(meepleType MeepleType) *Meeple {
+	for _, m := range p.Meeples {
+		if m.Feature != nil && m.Type == meepleType {
+			return m
+		}
+	}
+
+	return nil
+}
+
+// removeFromHand takes a tile the player's played out of their hand, only one when they're holding more than one of it
+func (p *Player) removeFromHand(rtg *tile.ReferenceTileGroup) {
+	for i, ht := range p.hand {
+		if ht == rtg {
+			hand := make([]*tile.ReferenceTileGroup, 0, len(p.hand)-1)
+			hand = append(hand, p.hand[:i]...)
+			p.hand = append(hand, p.hand[i+1:]...)
+			return
+		}
+	}
+}
+
 func (p *Player) DeterminePlacement(e *Engine, placementOptions []Placement) (*Placement, *MeeplePlacement) {
-	return p.AI.DeterminePlacement(e, placementOptions)
+	return p.AI.DeterminePlacement(e.Observe(p), copyPlacements(placementOptions))
 }
@@ -1,22 +1,58 @@
 package engine
 
 import (
+	"beeb/carcassonne/data"
+	"beeb/carcassonne/engine/board"
 	"beeb/carcassonne/engine/tile"
-	"math/rand"
+	"beeb/carcassonne/util"
 )
 
+// how much the basic AI values taking points away from other players, compared to scoring them itself
+const spiteFactor float32 = 0.5
+
+// how likely the dragon has to be to eat the basic AI's abbot before it's recalled
+const abbotRecallRisk float32 = 0.5
+
+// PlayerAI
+// decides where the player puts their tile, from what the player can see of the game
 type PlayerAI interface {
-	DeterminePlacement(e *Engine, placementOptions []Placement) (*Placement, *MeeplePlacement)
+	DeterminePlacement(o *Observation, placementOptions []Placement) (*Placement, *MeeplePlacement)
+}
+
+// PlayerAITypes
+// the built-in AIs by name, so they can be saved with the game and set up again when it's loaded
+var PlayerAITypes = map[string]func(p *Player) PlayerAI{
+	"basic": func(p *Player) PlayerAI {
+		return &BasicPlayerAI{}
+	},
+	"random": func(p *Player) PlayerAI {
+		return &RandomPlayerAI{}
+	},
+}
+
+// PlayerAITypeName
+// the name of the AI in PlayerAITypes, or an empty string for an AI which isn't built-in
+func PlayerAITypeName(ai PlayerAI) string {
+	switch ai.(type) {
+	case *BasicPlayerAI:
+		return "basic"
+	case *RandomPlayerAI:
+		return "random"
+	}
+
+	return ""
 }
 
-// BasicPlayerAI a simple AI which has incentives to create roads and castles
+// BasicPlayerAI
+// a simple AI which has incentives to create roads and castles.
+// like any other AI it only knows what the observation shows it, and it tries its placements on the observed board
 type BasicPlayerAI struct {
-	Player     *Player
 	Evaluation Evaluation
 }
 
 type Evaluation struct {
-	EvaluatedFeatures map[*tile.Feature]FeatureEvaluation
+	//in the order of the tile's features, so evaluations are deterministic
+	EvaluatedFeatures []FeatureEvaluation
 }
 
 type FeatureEvaluation struct {
@@ -30,58 +66,191 @@ type MeepleCostEvaluation struct {
 	MeepleCost        int
 	MeeplesReturned   []*Meeple
 	PlayerScoreChange map[*Player]int
+
+	//the chance of the dragon eating the meeple before the feature's finished
+	Risk float32
+	//points taken away from other players, like by the princess sending their follower home
+	Spite          int
+	PrincessMeeple *Meeple
+
+	//a follower is placed unless it's set, like to an abbot for a garden
+	MeepleType MeepleType
 }
 
+// MeeplePlacement
+// the meeple a player decided to place, and what it expected to gain from it.
+// the engine scores finished features itself, so ReturnedMeeples and ScoreGained are only the player's estimate.
+// instead of placing a meeple, the player can send a follower home with the princess, move the fairy next to one of theirs,
+// recall their abbot, or build a floor on a tower and capture a meeple in its reach
 type MeeplePlacement struct {
 	ParentFeature   *tile.Feature
 	SelectedMeeple  *Meeple
 	ReturnedMeeples []*Meeple
 	ScoreGained     int
+
+	//set when the meeple goes through a magic portal, ParentFeature is then a feature of the tile at this position
+	PortalPosition *util.Point[int]
+	PrincessMeeple *Meeple
+	FairyMeeple    *Meeple
+	RecalledAbbot  *Meeple
+	TowerPosition  *util.Point[int]
+	CapturedMeeple *Meeple
+}
+
+// basicObservation
+// what the basic AI works from while it decides, the observation, and what it needs from it more than once.
+// the board, the legal actions and the dragon are only worked out the first time they're needed
+type basicObservation struct {
+	*Observation
+
+	me    ObservedPlayer
+	rules data.RuleSet
+
+	board   *board.Board
+	actions map[placementKey][]Action
+	dragon  *dragonThreat
+}
+
+// placementKey where a placement puts the tile and which way it's facing, a placement's connections don't make it comparable
+type placementKey struct {
+	position      util.Point[int]
+	referenceTile *tile.ReferenceTile
+}
+
+func newBasicObservation(o *Observation) *basicObservation {
+	return &basicObservation{
+		Observation: o,
+		me:          o.Player(),
+		rules:       o.Rules(),
+		actions:     make(map[placementKey][]Action, 2),
+	}
+}
+
+func (bo *basicObservation) observedBoard() *board.Board {
+	if bo.board == nil {
+		bo.board = bo.Board()
+	}
+
+	return bo.board
+}
+
+func (bo *basicObservation) dragonThreat() *dragonThreat {
+	if bo.dragon == nil {
+		threat := newDragonThreat(bo.Observation, bo.rules)
+		bo.dragon = &threat
+	}
+
+	return bo.dragon
+}
+
+// placementActions the legal actions which put the tile down with the placement
+func (bo *basicObservation) placementActions(placement Placement) []Action {
+	key := placementKey{position: placement.Position, referenceTile: placement.ReferenceTile}
+	actions, exists := bo.actions[key]
+
+	if !exists {
+		actions = bo.LegalPlacementActions(placement)
+		bo.actions[key] = actions
+	}
+
+	return actions
+}
+
+// meepleAction
+// the legal action which places a meeple of the type on the feature with the placement, or on the first feature it can go on when f is nil.
+// a follower's power has to match when it's above 0. meeples through a portal aren't looked at
+func (bo *basicObservation) meepleAction(placement Placement, f *tile.Feature, meepleType MeepleType, power int) (Action, bool) {
+	for _, a := range bo.placementActions(placement) {
+		if a.MeepleFeature == nil || a.PortalPosition != nil || a.MeepleType != meepleType {
+			continue
+		}
+
+		if f != nil && a.MeepleFeature != f {
+			continue
+		}
+
+		if power > 0 && a.MeeplePower != power {
+			continue
+		}
+
+		return a, true
+	}
+
+	return Action{}, false
+}
+
+// actionMeeple one of the player's meeples in their supply, of the type and power the action places
+func (bo *basicObservation) actionMeeple(a Action) *Meeple {
+	for _, m := range bo.Meeples() {
+		if m.inSupply() && m.Type == a.MeepleType && m.Power == a.MeeplePower {
+			return m
+		}
+	}
+
+	return nil
+}
+
+func (bo *basicObservation) isMine(p *Player) bool {
+	return p != nil && p.Id == bo.me.Id
 }
 
-func (p *BasicPlayerAI) scoreMeepleCostEval(meepleCostEval MeepleCostEvaluation, e *Engine) float32 {
+// ownsChain whether the player making the observation owns the feature, the chain's players are the observed board's copies
+func (bo *basicObservation) ownsChain(featureChain *FeatureChain) bool {
+	for _, p := range featureChain.owners {
+		if bo.isMine(p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *BasicPlayerAI) scoreMeepleCostEval(bo *basicObservation, meepleCostEval MeepleCostEvaluation) float32 {
 
 	var playerRiskFactor float32 = 0.75
 
 	var directScoreFactor float32 = 1
 	var potentialScoreFactor float32 = 0.35
 
-	numMeeplesRemaining := p.Player.numRemainingMeeples()
-	var meeplesRemainingFactor = 2 - (float32(numMeeplesRemaining) / float32(MaxMeeples))
-
-	//see if we even have a meeple with enough power to do this
-	selectedMeeple := e.CurrentPlayer().GetAvailableMeepleWithPower(meepleCostEval.MeepleCost)
+	numMeeplesRemaining := bo.me.Meeples[Follower]
+	var meeplesRemainingFactor float32 = 1
 
-	//we can't place a meeple, but maybe we can worsen someone else's position?
-	//if we can't extend ours...
-	if selectedMeeple == nil {
-		//spiteFactor = 0.5
+	if numMeeples := bo.me.Followers; numMeeples > 0 {
+		meeplesRemainingFactor = 2 - (float32(numMeeplesRemaining) / float32(numMeeples))
 	}
 
 	directScore := directScoreFactor * float32(meepleCostEval.DirectScore)
 	potentialScore := playerRiskFactor * potentialScoreFactor * float32(meepleCostEval.PotentialScore)
 
+	//a meeple the dragon might eat is less likely to ever score
+	potentialScore *= 1 - meepleCostEval.Risk
+
 	//for each meeple we don't have in our pool, we like the direct score a little more
 	directScore *= meeplesRemainingFactor
 
-	return directScore + potentialScore
+	//we might not gain anything, but we can worsen someone else's position
+	spiteScore := spiteFactor * float32(meepleCostEval.Spite)
+
+	return directScore + potentialScore + spiteScore
 }
 
-func (p *BasicPlayerAI) DeterminePlacement(e *Engine, placementOptions []Placement) (*Placement, *MeeplePlacement) {
+func (p *BasicPlayerAI) DeterminePlacement(o *Observation, placementOptions []Placement) (*Placement, *MeeplePlacement) {
 	if len(placementOptions) == 0 {
 		return nil, nil
 	}
 
+	bo := newBasicObservation(o)
+
 	var bestScore float32 = 0
 	var bestMeepleCostEval MeepleCostEvaluation
 	var bestParentFeature *tile.Feature
 	var bestPlacement *Placement
 
 	for i, pl := range placementOptions {
-		eval := p.EvaluatePlacement(pl, e)
+		eval := p.evaluatePlacement(bo, pl)
 		for _, featureEval := range eval.EvaluatedFeatures {
 			for _, meepleCostEval := range featureEval.EvaluatedMeepleCosts {
-				calculatedScore := p.scoreMeepleCostEval(meepleCostEval, e)
+				calculatedScore := p.scoreMeepleCostEval(bo, meepleCostEval)
 				if calculatedScore > bestScore {
 					bestPlacement = &placementOptions[i]
 					bestParentFeature = featureEval.Feature.ParentFeature
@@ -93,11 +262,35 @@ func (p *BasicPlayerAI) DeterminePlacement(e *Engine, placementOptions []Placeme
 	}
 
 	if bestPlacement == nil {
-		randN := rand.Intn(len(placementOptions))
-		return &placementOptions[randN], nil
+		randN := o.Rand().Intn(len(placementOptions))
+		return &placementOptions[randN], p.specialMeeplePlacement(bo, placementOptions[randN])
+	}
+
+	if bestMeepleCostEval.PrincessMeeple != nil {
+		return bestPlacement, &MeeplePlacement{
+			PrincessMeeple: bestMeepleCostEval.PrincessMeeple,
+		}
 	}
 
-	selectedMeeple := e.CurrentPlayer().GetAvailableMeepleWithPower(bestMeepleCostEval.MeepleCost)
+	var selectedMeeple *Meeple
+
+	switch {
+	case bestMeepleCostEval.MeepleType != Follower:
+		if a, ok := bo.meepleAction(*bestPlacement, bestParentFeature, bestMeepleCostEval.MeepleType, 0); ok {
+			selectedMeeple = bo.actionMeeple(a)
+		}
+	case bestMeepleCostEval.MeepleCost > 0:
+		//big meeples are kept back until there are no normal meeples left
+		a, ok := bo.meepleAction(*bestPlacement, bestParentFeature, Follower, 1)
+
+		if !ok {
+			a, ok = bo.meepleAction(*bestPlacement, bestParentFeature, Follower, 0)
+		}
+
+		if ok {
+			selectedMeeple = bo.actionMeeple(a)
+		}
+	}
 
 	if selectedMeeple != nil {
 
@@ -107,6 +300,13 @@ func (p *BasicPlayerAI) DeterminePlacement(e *Engine, placementOptions []Placeme
 		}
 	}
 
+	//without a follower to place, a builder or pig can be put to work, or the fairy moved, instead
+	if selectedMeeple == nil {
+		if mp := p.specialMeeplePlacement(bo, *bestPlacement); mp != nil {
+			return bestPlacement, mp
+		}
+	}
+
 	return bestPlacement, &MeeplePlacement{
 		SelectedMeeple:  selectedMeeple,
 		ParentFeature:   bestParentFeature,
@@ -115,23 +315,257 @@ func (p *BasicPlayerAI) DeterminePlacement(e *Engine, placementOptions []Placeme
 	}
 }
 
-func (p *BasicPlayerAI) EvaluatePlacement(placement Placement, e *Engine) Evaluation {
+// specialMeeplePlacement
+// places the player's builder, or their pig if the builder's busy, on the first feature of the placement they can join.
+// failing that, their abbot is recalled if the dragon's likely to eat it, a tower floor is built to capture another player's meeple,
+// or the fairy is moved to protect whichever of the player's followers the dragon's most likely to eat
+func (p *BasicPlayerAI) specialMeeplePlacement(bo *basicObservation, placement Placement) *MeeplePlacement {
+	for _, meepleType := range []MeepleType{Builder, Pig} {
+		if a, ok := bo.meepleAction(placement, nil, meepleType, 0); ok {
+			return &MeeplePlacement{
+				SelectedMeeple: bo.actionMeeple(a),
+				ParentFeature:  a.MeepleFeature,
+			}
+		}
+	}
+
+	actions := bo.placementActions(placement)
+
+	for _, a := range actions {
+		if m := a.RecalledAbbot; m != nil && bo.dragonThreat().risk(m.Feature.ParentTile.Position) >= abbotRecallRisk {
+			return &MeeplePlacement{
+				RecalledAbbot: m,
+			}
+		}
+	}
+
+	if capture, ok := p.bestTowerCapture(bo, actions); ok {
+		return &MeeplePlacement{
+			TowerPosition:  capture.TowerPosition,
+			CapturedMeeple: capture.CapturedMeeple,
+		}
+	}
+
+	var fairyMeeple *Meeple
+	var fairyRisk float32 = -1
+
+	//she's worth having around for the points, even when nothing's at risk
+	if m := bo.FairyMeeple(); m != nil && m.Feature != nil && bo.isMine(m.ParentPlayer) {
+		fairyRisk = bo.dragonThreat().risk(m.Feature.ParentTile.Position)
+	}
+
+	for _, a := range actions {
+		if m := a.FairyMeeple; m != nil {
+			if risk := bo.dragonThreat().risk(m.Feature.ParentTile.Position); risk > fairyRisk {
+				fairyMeeple = m
+				fairyRisk = risk
+			}
+		}
+	}
+
+	if fairyMeeple == nil {
+		return nil
+	}
+
+	return &MeeplePlacement{
+		FairyMeeple: fairyMeeple,
+	}
+}
+
+// bestTowerCapture
+// the tower capture which takes the meeple off the feature worth the most so far, there isn't one when no tower can capture anything
+func (p *BasicPlayerAI) bestTowerCapture(bo *basicObservation, actions []Action) (Action, bool) {
+	var best Action
+	bestScore := -1
+
+	for _, a := range actions {
+		if a.TowerPosition == nil || a.CapturedMeeple == nil {
+			continue
+		}
+
+		featureChain := newFeatureChain(a.CapturedMeeple.Feature, bo.observedBoard(), &bo.rules)
+		featureChain.computeScore()
+
+		if featureChain.score > bestScore {
+			best = a
+			bestScore = featureChain.score
+		}
+	}
+
+	return best, bestScore >= 0
+}
+
+// evaluatePrincess
+// whether the princess on the tile can send home a follower which takes the castle away from its owner,
+// which is worth the castle's score in spite
+func (p *BasicPlayerAI) evaluatePrincess(bo *basicObservation, placement Placement, featureChain *FeatureChain) (MeepleCostEvaluation, bool) {
+	if featureChain.Feature.Type != tile.Castle || !placement.ReferenceTile.HasFeatureType(tile.Princess) {
+		return MeepleCostEvaluation{}, false
+	}
+
+	for _, a := range bo.placementActions(placement) {
+		m := a.PrincessMeeple
+
+		if m == nil || !featureChain.isOwner(m.ParentPlayer) {
+			continue
+		}
+
+		if _, exists := featureChain.FeaturesVisited[m.Feature]; !exists {
+			continue
+		}
+
+		owner := m.ParentPlayer
+
+		//they keep the castle if they'd still have as many meeples on it as anyone else
+		remaining := meeplePower(featureChain.playerMeeplesMap[owner]) - m.Power
+		keeps := remaining > 0
+
+		for other, meeples := range featureChain.playerMeeplesMap {
+			if other != owner && meeplePower(meeples) > remaining {
+				keeps = false
+			}
+		}
+
+		if keeps {
+			continue
+		}
+
+		return MeepleCostEvaluation{
+			Spite:             featureChain.score,
+			PrincessMeeple:    m,
+			PlayerScoreChange: map[*Player]int{owner: -featureChain.score},
+		}, true
+	}
+
+	return MeepleCostEvaluation{}, false
+}
+
+// DetermineDragonMove
+// sends the dragon where it eats the most of the other players' meeples, and the fewest of ours
+func (p *BasicPlayerAI) DetermineDragonMove(o *Observation, moves []util.Point[int]) util.Point[int] {
+	me := o.Player()
+	b := o.Board()
+
+	bestMove := moves[0]
+	var bestScore float32
+
+	for i, move := range moves {
+		var score float32
+
+		for _, m := range meeplesOnTile(b.Get(move)) {
+			if m.ParentPlayer.Id == me.Id {
+				score -= float32(m.Power)
+			} else {
+				score += spiteFactor * float32(m.Power)
+			}
+		}
+
+		if i == 0 || score > bestScore {
+			bestMove = move
+			bestScore = score
+		}
+	}
+
+	return bestMove
+}
+
+// dragonThreat
+// where the dragon is, how far it moves, and the chance of a dragon being drawn by someone before our next turn,
+// from the tiles the player hasn't seen yet
+type dragonThreat struct {
+	position *util.Point[int]
+	moves    int
+	drawn    float32
+}
+
+func newDragonThreat(o *Observation, rules data.RuleSet) dragonThreat {
+	threat := dragonThreat{
+		position: o.DragonPosition(),
+		moves:    rules.DragonMoves,
+	}
+
+	if threat.position == nil {
+		return threat
+	}
+
+	unseen := 0
+	dragons := 0
+
+	for name, count := range o.UnseenTiles() {
+		unseen += count
+
+		if rtg := o.ReferenceTileGroup(name); rtg != nil && rtg.Orientations[0].HasFeatureType(tile.Dragon) {
+			dragons += count
+		}
+	}
+
+	if unseen < 1 {
+		return threat
+	}
+
+	threat.drawn = float32(dragons*len(o.Players())) / float32(unseen)
+
+	if threat.drawn > 1 {
+		threat.drawn = 1
+	}
+
+	return threat
+}
+
+// risk
+// a rough chance of the dragon eating a meeple on the tile at pos, from the chance of a dragon being drawn,
+// and how close the dragon is. it can't reach further than it moves
+func (threat dragonThreat) risk(pos util.Point[int]) float32 {
+	if threat.position == nil || threat.drawn == 0 {
+		return 0
+	}
+
+	d := threat.position.Subtract(pos)
+	distance := abs(d.X) + abs(d.Y)
+
+	if distance > threat.moves {
+		return 0
+	}
+
+	return threat.drawn * float32(threat.moves+1-distance) / float32(threat.moves+1)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+
+	return n
+}
+
+// EvaluatePlacement
+// what each feature of the tile is worth to the player with the placement, tried on the observed board
+func (p *BasicPlayerAI) EvaluatePlacement(placement Placement, o *Observation) Evaluation {
+	return p.evaluatePlacement(newBasicObservation(o), placement)
+}
+
+func (p *BasicPlayerAI) evaluatePlacement(bo *basicObservation, placement Placement) Evaluation {
 
 	eval := Evaluation{}
-	eval.EvaluatedFeatures = make(map[*tile.Feature]FeatureEvaluation)
+	eval.EvaluatedFeatures = make([]FeatureEvaluation, 0, 4)
 
-	t := e.TileFactory.NewTileFromReference(placement.ReferenceTile)
-	e.GameBoard.PlaceTile(placement.Position, t)
+	tileFactory := tile.TileFactory{}
+	t := tileFactory.NewTileFromReference(placement.ReferenceTile)
+	b := bo.observedBoard()
+	remove := b.PlaceHypotheticalTile(placement.Position, t)
 
 	visitedFeaturesOfTile := make(map[*tile.Feature]struct{})
 
+	//nothing can go on a volcano, the dragon's there
+	volcano := placement.ReferenceTile.HasFeatureType(tile.Volcano)
+
 	for _, f := range t.Features {
 		//avoid re-evaluating features later
 		if _, exists := visitedFeaturesOfTile[f]; exists {
 			continue
 		}
 
-		featureChain := newFeatureChain(f)
+		featureChain := newFeatureChain(f, b, &bo.rules)
 
 		//support to avoid re-evaluating features later
 		for f := range featureChain.FeaturesVisited {
@@ -151,11 +585,33 @@ func (p *BasicPlayerAI) EvaluatePlacement(placement Placement, e *Engine) Evalua
 		featureChain.computePlayerMeeplesMap()
 		featureChain.computeOwners()
 
-		//just don't add to features that are owned, but not by you
-		if featureChain.hasOwner() && !featureChain.isOwner(p.Player) {
+		//just don't add to features that are owned, but not by you, unless the princess can take them away
+		if featureChain.hasOwner() && !bo.ownsChain(&featureChain) {
+			if meepleCostEval, ok := p.evaluatePrincess(bo, placement, &featureChain); ok {
+				eval.EvaluatedFeatures = append(eval.EvaluatedFeatures, FeatureEvaluation{
+					Feature:              f,
+					EvaluatedMeepleCosts: []MeepleCostEvaluation{meepleCostEval},
+				})
+			}
+
+			continue
+		}
+
+		if volcano {
 			continue
 		}
 
+		meepleType := Follower
+
+		//only an abbot can go in a garden
+		if f.Type == tile.Garden {
+			if bo.me.Meeples[Abbot] < 1 {
+				continue
+			}
+
+			meepleType = Abbot
+		}
+
 		featureEval := FeatureEvaluation{}
 		featureEval.Feature = f
 		featureEval.EvaluatedMeepleCosts = make([]MeepleCostEvaluation, 0, 4)
@@ -167,7 +623,7 @@ func (p *BasicPlayerAI) EvaluatePlacement(placement Placement, e *Engine) Evalua
 
 		meepleCost := 1
 
-		if featureChain.isOwner(p.Player) {
+		if bo.ownsChain(&featureChain) {
 			meepleCost = 0
 		}
 
@@ -177,6 +633,11 @@ func (p *BasicPlayerAI) EvaluatePlacement(placement Placement, e *Engine) Evalua
 			PotentialScore:    featureChain.potential(),
 			MeeplesReturned:   meeplesReturned,
 			PlayerScoreChange: make(map[*Player]int),
+			MeepleType:        meepleType,
+		}
+
+		if meepleCost > 0 && !featureChain.isComplete {
+			meepleCostEval.Risk = bo.dragonThreat().risk(placement.Position)
 		}
 
 		for _, p := range featureChain.owners {
@@ -185,13 +646,13 @@ func (p *BasicPlayerAI) EvaluatePlacement(placement Placement, e *Engine) Evalua
 
 		featureEval.EvaluatedMeepleCosts = append(featureEval.EvaluatedMeepleCosts, meepleCostEval)
 
-		eval.EvaluatedFeatures[f] = featureEval
+		eval.EvaluatedFeatures = append(eval.EvaluatedFeatures, featureEval)
 
 		//estimate chance of meeple returning before the game ends
 
 	}
 
-	e.GameBoard.RemoveTileAt(placement.Position)
+	remove()
 
 	return eval
 }
@@ -0,0 +1,71 @@
+package engine
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math/rand"
+	"reflect"
+)
+
+// countedSource
+// a random source which keeps track of how many values it has given out,
+// so its state can be saved, and restored later by replaying that many draws from the seed
+type countedSource struct {
+	src   rand.Source64
+	draws uint64
+}
+
+func newCountedSource(seed int64, draws uint64) *countedSource {
+	cs := &countedSource{}
+	cs.src = rand.NewSource(seed).(rand.Source64)
+
+	for cs.draws < draws {
+		cs.Int63()
+	}
+
+	return cs
+}
+
+func (cs *countedSource) Int63() int64 {
+	cs.draws++
+	return cs.src.Int63()
+}
+
+func (cs *countedSource) Uint64() uint64 {
+	cs.draws++
+	return cs.src.Uint64()
+}
+
+func (cs *countedSource) Seed(seed int64) {
+	cs.draws = 0
+	cs.src.Seed(seed)
+}
+
+// clone copies the source's state directly, rather than replaying every draw from the seed.
+// the standard library's source has no exported state, but it is a plain value behind a pointer, so reflect can copy it
+func (cs *countedSource) clone() *countedSource {
+	src := reflect.ValueOf(cs.src)
+	srcCopy := reflect.New(src.Elem().Type())
+	srcCopy.Elem().Set(src.Elem())
+
+	return &countedSource{
+		src:   srcCopy.Interface().(rand.Source64),
+		draws: cs.draws,
+	}
+}
+
+// aiSeed
+// a seed for an AI's random source, hashed from the game's seed, the turn and the player's index,
+// so no two turns or players share one, however many of them there are
+func aiSeed(seed int64, turn int, index int) int64 {
+	var b [24]byte
+
+	binary.LittleEndian.PutUint64(b[0:], uint64(seed))
+	binary.LittleEndian.PutUint64(b[8:], uint64(turn))
+	binary.LittleEndian.PutUint64(b[16:], uint64(index))
+
+	h := fnv.New64a()
+	h.Write(b[:])
+
+	return int64(h.Sum64())
+}
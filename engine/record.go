@@ -0,0 +1,225 @@
+package engine
+
+import (
+	"beeb/carcassonne/data"
+	"beeb/carcassonne/engine/tile"
+	"beeb/carcassonne/util"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// GameRecordVersion
+// bump this whenever the shape of GameRecord changes
+const GameRecordVersion = 2
+
+var ErrReplayDiverged = errors.New("replay diverged from the game record")
+
+// GameRecord
+// a compact log of a game, everything needed to play it back move for move
+type GameRecord struct {
+	Version   int
+	Seed      int64
+	DeckFile  string
+	BoardSize int
+	Rules     *data.RuleSet `json:",omitempty"`
+	Players   []RecordPlayer
+	Turns     []RecordTurn
+}
+
+type RecordPlayer struct {
+	Name  string
+	Color string
+	AI    string
+	//the player's own supply, when the game was set up with one
+	Meeples *data.MeepleSupply `json:",omitempty"`
+}
+
+type RecordTurn struct {
+	Turn   int
+	Player int
+	//every tile drawn this turn, the last one is the tile played, any others were unplayable.
+	//when the game's played with hands, they're the tiles drawn into the player's hand, which can be none once the deck's run out
+	Draws  []string     `json:",omitempty"`
+	Action *StateAction `json:",omitempty"`
+	//where the dragon was moved to, in order, when the tile played had a dragon on it
+	DragonMoves []util.Point[int] `json:",omitempty"`
+}
+
+func NewGameRecord(e *Engine) *GameRecord {
+	record := &GameRecord{
+		Version:   GameRecordVersion,
+		Seed:      e.Seed,
+		DeckFile:  e.GameData.DeckFilePath,
+		BoardSize: e.BoardSize,
+		Rules:     e.Rules,
+	}
+
+	record.Players = make([]RecordPlayer, len(e.Players))
+
+	for i, p := range e.Players {
+		record.Players[i] = RecordPlayer{
+			Name:  p.Name,
+			Color: data.FormatColor(p.Color),
+			AI:    PlayerAITypeName(p.AI),
+		}
+
+		if e.Setup != nil && i < len(e.Setup.Players) {
+			record.Players[i].Meeples = e.Setup.Players[i].Meeples
+		}
+	}
+
+	record.Turns = make([]RecordTurn, 0, 128)
+
+	return record
+}
+
+// recording is skipped when there's no record, like for an engine loaded part way through a game
+func (r *GameRecord) recordDraw(turn int, player int, rtg *tile.ReferenceTileGroup) {
+	if r == nil {
+		return
+	}
+
+	if l := len(r.Turns); l > 0 && r.Turns[l-1].Turn == turn {
+		r.Turns[l-1].Draws = append(r.Turns[l-1].Draws, rtg.Name)
+		return
+	}
+
+	r.Turns = append(r.Turns, RecordTurn{
+		Turn:   turn,
+		Player: player,
+		Draws:  []string{rtg.Name},
+	})
+}
+
+func (r *GameRecord) recordAction(turn int, player int, action *StateAction) {
+	if r == nil {
+		return
+	}
+
+	//a tile played from a hand may not have drawn anything this turn
+	if l := len(r.Turns); l < 1 || r.Turns[l-1].Turn != turn {
+		r.Turns = append(r.Turns, RecordTurn{
+			Turn:   turn,
+			Player: player,
+		})
+	}
+
+	r.Turns[len(r.Turns)-1].Action = action
+}
+
+func (r *GameRecord) recordDragonMove(turn int, pos util.Point[int]) {
+	if r == nil || len(r.Turns) < 1 || r.Turns[len(r.Turns)-1].Turn != turn {
+		return
+	}
+
+	r.Turns[len(r.Turns)-1].DragonMoves = append(r.Turns[len(r.Turns)-1].DragonMoves, pos)
+}
+
+// Replay
+// plays a recorded game back through the engine. the tiles drawn and the actions taken come from the record,
+// rather than the deck order and the players' AIs, so the game plays out the same way even if the AIs have changed.
+// the game data must be loaded from the record's deck file
+func Replay(gameData *data.GameData, record *GameRecord) (*Engine, error) {
+	if record.Version != GameRecordVersion {
+		return nil, fmt.Errorf("%w: version %d, expected %d", ErrUnsupportedRecordVersion, record.Version, GameRecordVersion)
+	}
+
+	setup := data.DefaultGameSetup(len(record.Players))
+
+	for i, rp := range record.Players {
+		setup.Players[i] = data.PlayerSetup{
+			Name:    rp.Name,
+			Color:   rp.Color,
+			AI:      rp.AI,
+			Meeples: rp.Meeples,
+		}
+
+		//the game's played back with the basic AI in place of any AI which isn't built-in
+		if _, exists := PlayerAITypes[rp.AI]; !exists {
+			setup.Players[i].AI = ""
+		}
+	}
+
+	//the game's played by the record's rules, which may not be the game data's
+	if record.Rules != nil {
+		if err := record.Rules.Validate(); err != nil {
+			return nil, err
+		}
+
+		recordData := *gameData
+		recordData.Rules = record.Rules
+		gameData = &recordData
+	}
+
+	e, err := NewEngineFromSetup(gameData, record.BoardSize, setup, record.Seed)
+
+	if err != nil {
+		return nil, err
+	}
+
+	e.Record = NewGameRecord(e)
+
+	for _, turn := range record.Turns {
+		e.replayDraws = append(e.replayDraws, turn.Draws...)
+		e.replayDragonMoves = append(e.replayDragonMoves, turn.DragonMoves...)
+	}
+
+	if err := e.StepUntilDecision(); err != nil {
+		return e, err
+	}
+
+	for _, turn := range record.Turns {
+		if turn.Action == nil {
+			continue
+		}
+
+		if e.GameOver {
+			return e, fmt.Errorf("%w: game ended before turn %d", ErrReplayDiverged, turn.Turn)
+		}
+
+		if e.TurnCounter != turn.Turn || e.CurrentPlayerIndex != turn.Player {
+			return e, fmt.Errorf("%w: expected turn %d for player %d, got turn %d for player %d",
+				ErrReplayDiverged, turn.Turn, turn.Player, e.TurnCounter, e.CurrentPlayerIndex)
+		}
+
+		action, err := e.stateAction(*turn.Action)
+
+		if err != nil {
+			return e, err
+		}
+
+		if err = e.Apply(action); err != nil {
+			return e, fmt.Errorf("%w: turn %d: %v", ErrReplayDiverged, turn.Turn, err)
+		}
+	}
+
+	return e, nil
+}
+
+func (r *GameRecord) Write(path string) error {
+	jsonBytes, err := json.Marshal(r)
+
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, jsonBytes, 0644)
+}
+
+func ReadGameRecord(path string) (*GameRecord, error) {
+	jsonBytes, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	record := &GameRecord{}
+
+	if err = json.Unmarshal(jsonBytes, record); err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
@@ -0,0 +1,78 @@
+package engine_test
+
+import (
+	"beeb/carcassonne/engine"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestReplay(t *testing.T) {
+	gameData := loadGameData(t, "../data/standard_deck.yml")
+
+	e1 := newEngine(t, gameData, 16, 3, 9)
+
+	for !e1.GameOver {
+		e1.Step()
+	}
+
+	path := filepath.Join(t.TempDir(), "game.json")
+
+	if err := e1.Record.Write(path); err != nil {
+		t.Fatal(err)
+	}
+
+	record, err := engine.ReadGameRecord(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e2, err := engine.Replay(gameData, record)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !e2.GameOver {
+		t.Fatal("replayed game did not finish")
+	}
+
+	//the ai doesn't run during a replay, so only the board and players are expected to match
+	state1 := engine.NewEngineState(e1)
+	state2 := engine.NewEngineState(e2)
+
+	tiles1, _ := json.Marshal(state1.Tiles)
+	tiles2, _ := json.Marshal(state2.Tiles)
+
+	if !bytes.Equal(tiles1, tiles2) {
+		t.Error("replayed board does not match the recorded game")
+	}
+
+	for i, p1 := range state1.Players {
+		p2 := state2.Players[i]
+
+		if p1.Score != p2.Score {
+			t.Errorf("player %d scored %d in the replay, expected %d", i, p2.Score, p1.Score)
+		}
+
+		//meeple ids are random, so compare where each one ended up
+		for j, m1 := range p1.Meeples {
+			f1, _ := json.Marshal(m1.Feature)
+			f2, _ := json.Marshal(p2.Meeples[j].Feature)
+
+			if !bytes.Equal(f1, f2) {
+				t.Errorf("player %d meeple %d is in a different place in the replay", i, j)
+			}
+		}
+	}
+
+	//a record from another version isn't mistaken for a saved engine state
+	record.Version++
+
+	if _, err = engine.Replay(gameData, record); !errors.Is(err, engine.ErrUnsupportedRecordVersion) || errors.Is(err, engine.ErrUnsupportedStateVersion) {
+		t.Errorf("Replay() of a record from another version, error = %v, want %v", err, engine.ErrUnsupportedRecordVersion)
+	}
+}
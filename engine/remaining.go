@@ -0,0 +1,107 @@
+package engine
+
+import (
+	"beeb/carcassonne/engine/tile"
+	"beeb/carcassonne/util"
+)
+
+// Fillability
+// how many of the unplayed tiles could go in an open position, in any orientation,
+// and the chance of a tile picked at random from the unplayed tiles fitting there
+type Fillability struct {
+	Position    util.Point[int]
+	Tiles       int
+	Probability float64
+}
+
+// RemainingTiles
+// how many of each tile are still to be played, by name. they're the tiles in the deck file less those on the board
+// and those thrown out because they couldn't go anywhere, so the tiles in the players' hands are counted.
+// which river tiles are in the game depends on the river variant, so they're what's left of the river deck
+func (e *Engine) RemainingTiles() map[string]int {
+	remaining := make(map[string]int, len(e.GameData.DeckInfo.Deck))
+
+	for name, count := range e.GameData.DeckInfo.Deck {
+		if rtg, exists := e.GameData.ReferenceTileGroups[name]; exists && !rtg.IsRiverTile() {
+			remaining[name] = count
+		}
+	}
+
+	for _, t := range e.GameBoard.PlacedTiles() {
+		if t.Reference.EdgeSignature.Contains(tile.River) {
+			continue
+		}
+
+		if remaining[t.Reference.Name] > 0 {
+			remaining[t.Reference.Name]--
+		}
+	}
+
+	//a thrown out river tile was never put back in the river deck, so it's already not counted
+	for _, rtg := range e.discardPile {
+		if !rtg.IsRiverTile() && remaining[rtg.Name] > 0 {
+			remaining[rtg.Name]--
+		}
+	}
+
+	for _, rtg := range e.RiverDeck.Tiles {
+		remaining[rtg.Name]++
+	}
+
+	//the river tile being held has been drawn, but not played yet
+	if rtg := e.HeldRefTileGroup; rtg != nil && rtg.IsRiverTile() {
+		remaining[rtg.Name]++
+	}
+
+	for name, count := range remaining {
+		if count < 1 {
+			delete(remaining, name)
+		}
+	}
+
+	return remaining
+}
+
+// Fillability
+// for each open position on the board, in the order of Board.OpenPositionsList, how many of the remaining tiles
+// have edges which fit the position's neighbours. a position no tile fits can't be filled, so a feature
+// which needs a tile there can't be finished
+func (e *Engine) Fillability() []Fillability {
+	remaining := e.RemainingTiles()
+
+	total := 0
+	for _, count := range remaining {
+		total += count
+	}
+
+	positions := e.GameBoard.OpenPositionsList()
+	fillability := make([]Fillability, len(positions))
+
+	for i, pos := range positions {
+		sig := e.GameBoard.OpenPositions[pos]
+		fillability[i].Position = pos
+
+		for name, count := range remaining {
+			if fits(e.GameData.ReferenceTileGroups[name], sig) {
+				fillability[i].Tiles += count
+			}
+		}
+
+		if total > 0 {
+			fillability[i].Probability = float64(fillability[i].Tiles) / float64(total)
+		}
+	}
+
+	return fillability
+}
+
+// fits whether the tile could go somewhere with the edge signature, in any orientation
+func fits(rtg *tile.ReferenceTileGroup, sig *tile.EdgeSignature) bool {
+	for _, rt := range rtg.Orientations {
+		if rt.EdgeSignature.Compatible(sig) {
+			return true
+		}
+	}
+
+	return false
+}
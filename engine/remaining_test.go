@@ -0,0 +1,92 @@
+package engine_test
+
+import (
+	"beeb/carcassonne/engine"
+	"beeb/carcassonne/engine/turnStage"
+	"beeb/carcassonne/util"
+	"testing"
+)
+
+func TestEngine_RemainingTiles(t *testing.T) {
+	gameData := loadGameData(t, "../data/standard_deck.yml")
+	//the board's small enough that tiles get thrown out
+	e := newEngine(t, gameData, 10, 3, 5)
+
+	total := 0
+	for _, count := range gameData.DeckInfo.Deck {
+		total += count
+	}
+
+	discarded := 0
+
+	e.Subscribe(engine.EventSubscriberFunc(func(e *engine.Engine, event engine.Event) {
+		if event, ok := event.(engine.TileDiscardedEvent); ok && !event.Reshuffled {
+			discarded++
+		}
+	}))
+
+	countRemaining := func() int {
+		remaining := 0
+		for _, count := range e.RemainingTiles() {
+			remaining += count
+		}
+
+		return remaining
+	}
+
+	if remaining := countRemaining(); remaining != total {
+		t.Fatalf("%d tiles remaining at the start of the game, expected %d", remaining, total)
+	}
+
+	for !e.GameOver {
+		if err := e.Step(); err != nil {
+			t.Fatal(err)
+		}
+
+		if e.TurnStage != turnStage.PlaceTile || e.GameBoard.PlacedTileCount < 1 {
+			continue
+		}
+
+		//thrown out tiles can't be played any more
+		if remaining, expected := countRemaining(), total-e.GameBoard.PlacedTileCount-discarded; remaining != expected {
+			t.Fatalf("turn %d: %d tiles remaining with %d placed and %d thrown out, expected %d",
+				e.TurnCounter, remaining, e.GameBoard.PlacedTileCount, discarded, expected)
+		}
+
+		tiles := make(map[util.Point[int]]int)
+
+		for _, f := range e.Fillability() {
+			if f.Probability < 0 || f.Probability > 1 {
+				t.Errorf("turn %d: %v has a probability of %f", e.TurnCounter, f.Position, f.Probability)
+			}
+
+			tiles[f.Position] = f.Tiles
+		}
+
+		//the held tile fits everywhere it can be placed
+		for _, placement := range e.CurrentPossibleTilePlacements {
+			if tiles[placement.Position] < 1 {
+				t.Errorf("turn %d: no tiles fit %v, but %s can be placed there", e.TurnCounter, placement.Position, placement.ReferenceTile.Name)
+			}
+		}
+	}
+
+	if discarded < 1 {
+		t.Fatal("expected a tile to be thrown out")
+	}
+
+	if remaining := countRemaining(); remaining != 0 {
+		t.Errorf("%d tiles remaining at the end of the game, expected none", remaining)
+	}
+
+	//the thrown out tiles are saved with the game
+	loaded, err := engine.LoadEngineState(gameData, engine.NewEngineState(e))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if remaining := loaded.RemainingTiles(); len(remaining) != 0 {
+		t.Errorf("%v remaining in the loaded game, expected none", remaining)
+	}
+}
@@ -0,0 +1,392 @@
+package engine
+
+import (
+	"beeb/carcassonne/data"
+	"beeb/carcassonne/engine/deck"
+	"beeb/carcassonne/engine/tile"
+	"beeb/carcassonne/util"
+	"beeb/carcassonne/util/directions"
+	"math/rand"
+	"sort"
+)
+
+// RiverVariant
+// how the river is played at the start of the game. it builds the river deck, which is drawn from before the main deck,
+// and narrows down where each river tile can go. the river's progress is kept by the engine, so it's saved with the game
+type RiverVariant interface {
+	BuildDeck(gameData *data.GameData, r *rand.Rand) *deck.Deck
+	RestrictPlacements(e *Engine, placements []Placement) []Placement
+}
+
+// RiverVariants the river variants by the name the rule set picks them with
+var RiverVariants = map[string]RiverVariant{
+	data.NoRiver:      NoRiver{},
+	data.ClassicRiver: ClassicRiver{},
+	data.RiverII:      RiverII{},
+}
+
+// River
+// the river variant the game is played with, the classic river for rule sets which don't pick one
+func (e *Engine) River() RiverVariant {
+	if river, exists := RiverVariants[e.Rules.River]; exists {
+		return river
+	}
+
+	return ClassicRiver{}
+}
+
+// the river ii tiles which have a set place in the river
+const (
+	riverSpring = "RiverTerminus"
+	riverFork   = "RiverFork"
+	riverLake   = "RiverLake"
+)
+
+// NoRiver
+// the game starts straight from the main deck, any river tiles in the deck file are left out
+type NoRiver struct{}
+
+func (NoRiver) BuildDeck(gameData *data.GameData, r *rand.Rand) *deck.Deck {
+	return &deck.Deck{}
+}
+
+func (NoRiver) RestrictPlacements(e *Engine, placements []Placement) []Placement {
+	return placements
+}
+
+// ClassicRiver
+// a single river from a spring to a lake, which are both the terminus tile. each tile carries on from the last one placed,
+// and curves turn back the other way to the last curve, towards the middle of the board.
+// the river ii tiles are left out
+type ClassicRiver struct{}
+
+func (ClassicRiver) BuildDeck(gameData *data.GameData, r *rand.Rand) *deck.Deck {
+	d := deck.Build(gameData, func(rtg *tile.ReferenceTileGroup) bool {
+		return rtg.IsRiverTile() && !rtg.IsRiverTerminus() && !isRiverIITile(rtg)
+	})
+
+	terminus := gameData.ReferenceTileGroups[riverSpring]
+
+	d.Shuffle(r)
+
+	d.Prepend(terminus)
+	d.Append(terminus)
+
+	return d
+}
+
+func (ClassicRiver) RestrictPlacements(e *Engine, placements []Placement) []Placement {
+
+	permittedPlacements := make([]Placement, 0)
+	permittedCurvedPlacements := make([]Placement, 0)
+
+	for _, placement := range placements {
+
+		connectedFeatures := placement.ConnectedFeatures
+
+		for _, cf := range connectedFeatures {
+			dir := cf.EdgeA
+
+			connectedTilePos := placement.Position.EdgePos(dir)
+
+			//must be connected to the last tile placed
+			if connectedTilePos != e.lastRiverTile.Position {
+				continue
+			}
+
+			//must be a river connection
+			if cf.FeatureA.Type != tile.River {
+				continue
+			}
+
+			//dont let the river turn the same way twice
+			//if the new tile is curving, keep track of which way it was oriented
+			//the next curved tile must be oriented 180 deg different from this tile
+			if cf.FeatureA.ParentRefenceTileGroup.Orientations[0].EdgeSignature.IsRiverCurving() {
+
+				//this is the first curve of the river, it can go either way
+				if e.lastRiverTurn == 1 {
+					permittedCurvedPlacements = append(permittedCurvedPlacements, placement)
+					continue
+				}
+
+				// for the first turn, let it turn whatever way it wants
+				if !e.isFirstRiverTurn && e.Rules.RiverAlternatingTurns {
+					//next piece must be 180 degrees out of phase with the last
+					nextCurveOrientation := (e.lastRiverTurn + 180) % 360
+
+					if placement.ReferenceTile.Orientation != nextCurveOrientation {
+						continue
+					}
+				}
+
+				permittedCurvedPlacements = append(permittedCurvedPlacements, placement)
+				continue
+			}
+
+			permittedPlacements = append(permittedPlacements, placement)
+		}
+	}
+
+	// second pass to preferentially pick inward facing curves
+	// only relevant when there are multiple valid curve placements
+	if len(permittedCurvedPlacements) > 1 {
+		permittedPlacements = append(permittedPlacements, nearestCenter(e, permittedCurvedPlacements))
+	}
+
+	return permittedPlacements
+}
+
+// RiverII
+// a river which forks in two straight after the spring, one branch ends in a volcano and the other in a lake.
+// each tile can carry on from either open end of the river, as long as it leaves room on the board for the river to go on
+type RiverII struct{}
+
+func (RiverII) BuildDeck(gameData *data.GameData, r *rand.Rand) *deck.Deck {
+	d := deck.Build(gameData, func(rtg *tile.ReferenceTileGroup) bool {
+		return rtg.IsRiverTile() && !rtg.IsRiverTerminus() && rtg.Name != riverFork && rtg.Name != riverLake
+	})
+
+	d.Shuffle(r)
+
+	if gameData.DeckInfo.Deck[riverFork] > 0 {
+		d.Prepend(gameData.ReferenceTileGroups[riverFork])
+	}
+
+	spring := gameData.ReferenceTileGroups[riverSpring]
+	d.Prepend(spring)
+
+	//the volcano ends one branch somewhere along the way, the lake ends the other last of all
+	if gameData.DeckInfo.Deck[riverLake] > 0 {
+		d.Append(gameData.ReferenceTileGroups[riverLake])
+	} else {
+		d.Append(spring)
+	}
+
+	return d
+}
+
+func (RiverII) RestrictPlacements(e *Engine, placements []Placement) []Placement {
+	permittedPlacements := make([]Placement, 0, len(placements))
+
+	//the curves carrying on from each end of the river, in the order the ends were found
+	riverEnds := make([]util.Point[int], 0, 2)
+	curvedPlacements := make(map[util.Point[int]][]Placement, 2)
+
+	openEnds := openRiverEnds(e)
+
+	for _, placement := range placements {
+		riverEnd, connected := riverConnection(placement)
+
+		if !connected || !riverEndsClear(e, placement, openEnds) || blocksRiverEnd(placement, openEnds) {
+			continue
+		}
+
+		if !placement.ReferenceTile.EdgeSignature.IsRiverCurving() {
+			permittedPlacements = append(permittedPlacements, placement)
+			continue
+		}
+
+		if _, exists := curvedPlacements[riverEnd]; !exists {
+			riverEnds = append(riverEnds, riverEnd)
+		}
+
+		curvedPlacements[riverEnd] = append(curvedPlacements[riverEnd], placement)
+	}
+
+	//each end only curves the way with the most room for the river to go on, so it doesn't wind around into itself
+	for _, riverEnd := range riverEnds {
+		permittedPlacements = append(permittedPlacements, mostRoom(e, curvedPlacements[riverEnd]))
+	}
+
+	return permittedPlacements
+}
+
+func isRiverIITile(rtg *tile.ReferenceTileGroup) bool {
+	return rtg.Name == riverFork || rtg.Name == riverLake || rtg.Orientations[0].HasFeatureType(tile.Volcano)
+}
+
+// riverConnection
+// the position of the tile already on the board the placement carries the river on from, if it does
+func riverConnection(placement Placement) (util.Point[int], bool) {
+	for _, cf := range placement.ConnectedFeatures {
+		if cf.FeatureA.Type == tile.River {
+			return placement.Position.EdgePos(cf.EdgeA), true
+		}
+	}
+
+	return util.Point[int]{}, false
+}
+
+// riverEndsClear
+// whether every end of the river the placement leaves open has room for the river to go on,
+// an empty space on the board with nothing else around it to run into, including the river's other open ends
+func riverEndsClear(e *Engine, placement Placement, openEnds []util.Point[int]) bool {
+	for edge, feature := range placement.ReferenceTile.EdgeFeatures {
+		if feature.Type != tile.River {
+			continue
+		}
+
+		neighbourPos := placement.Position.EdgePos(directions.Direction(edge))
+
+		if e.GameBoard.Get(neighbourPos) != nil {
+			continue
+		}
+
+		if !e.GameBoard.IsInBounds(neighbourPos) {
+			return false
+		}
+
+		//the next tile needs room to carry the river on too, so it can't be at the edge of the board
+		for _, pos := range neighbourPos.OrthogonalNeighbours() {
+			if !e.GameBoard.IsInBounds(pos) || (pos != placement.Position && e.GameBoard.Get(pos) != nil) {
+				return false
+			}
+		}
+
+		for _, openEnd := range openEnds {
+			if openEnd != placement.Position && (openEnd == neighbourPos || isOrthogonalNeighbour(openEnd, neighbourPos)) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// openRiverEnds the empty spaces the river on the board runs into, where it carries on from next
+func openRiverEnds(e *Engine) []util.Point[int] {
+	openEnds := make([]util.Point[int], 0, 2)
+
+	for _, t := range e.GameBoard.PlacedTiles() {
+		for edge, feature := range t.EdgeFeatures {
+			if feature.Type != tile.River {
+				continue
+			}
+
+			pos := t.Position.EdgePos(directions.Direction(edge))
+
+			if e.GameBoard.Get(pos) == nil {
+				openEnds = append(openEnds, pos)
+			}
+		}
+	}
+
+	return openEnds
+}
+
+// blocksRiverEnd
+// whether the placement would sit next to where another end of the river carries on, boxing it in
+func blocksRiverEnd(placement Placement, openEnds []util.Point[int]) bool {
+	for _, openEnd := range openEnds {
+		if openEnd != placement.Position && isOrthogonalNeighbour(openEnd, placement.Position) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isOrthogonalNeighbour(p1 util.Point[int], p2 util.Point[int]) bool {
+	for _, pos := range p1.OrthogonalNeighbours() {
+		if pos == p2 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// mostRoom
+// the placement which points the river at the most empty space on the board, the nearest to the middle of the board on a tie
+func mostRoom(e *Engine, placements []Placement) Placement {
+	best := placements[0]
+	bestRoom := -1
+	bestDist := 0.0
+
+	for _, placement := range placements {
+		pos := placement.Position.Add(computeRiverDirection(e, placement))
+		dist := e.GameBoard.Center().Subtract(pos).Magnitude()
+		room := 0
+
+		for y := pos.Y - 2; y <= pos.Y+2; y++ {
+			for x := pos.X - 2; x <= pos.X+2; x++ {
+				p := util.Point[int]{X: x, Y: y}
+
+				if p != placement.Position && e.GameBoard.IsInBounds(p) && e.GameBoard.Get(p) == nil {
+					room++
+				}
+			}
+		}
+
+		if room > bestRoom || (room == bestRoom && dist < bestDist) {
+			best = placement
+			bestRoom = room
+			bestDist = dist
+		}
+	}
+
+	return best
+}
+
+// nearestCenter
+// the placement which points the river closest to the middle of the board
+func nearestCenter(e *Engine, placements []Placement) Placement {
+	magnitudes := make([]struct {
+		p Placement
+		m float64
+	}, 0, len(placements))
+
+	for _, placement := range placements {
+		middle := e.GameBoard.Center()
+
+		// add the direction the river is pointing to the tile's position
+		// then calculate distance to center
+		dir := computeRiverDirection(e, placement)
+		pos := placement.Position.Add(dir)
+
+		dist := middle.Subtract(pos)
+		mag := dist.Magnitude()
+
+		magnitudes = append(magnitudes, struct {
+			p Placement
+			m float64
+		}{
+			p: placement,
+			m: mag,
+		})
+	}
+
+	sort.Slice(magnitudes, func(i, j int) bool {
+		return magnitudes[i].m < magnitudes[j].m
+	})
+
+	return magnitudes[0].p
+}
+
+// computeRiverDirection
+// a river tiles pointed direction can be determined by looking at the open-end of the river on the tile
+func computeRiverDirection(eng *Engine, placement Placement) util.Point[int] {
+
+	for edge, feature := range placement.ReferenceTile.EdgeFeatures {
+		if feature.Type != tile.River {
+			continue
+		}
+
+		// river feature
+
+		edgeDir := directions.Direction(edge)
+		neighborTilePos := placement.Position.EdgePos(edgeDir)
+		if eng.GameBoard.Get(neighborTilePos) != nil {
+			continue
+		}
+
+		// river feature which is open
+
+		diff := neighborTilePos.Subtract(placement.Position)
+
+		return diff
+	}
+
+	return util.Point[int]{}
+}
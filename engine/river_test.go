@@ -0,0 +1,68 @@
+package engine_test
+
+import (
+	"beeb/carcassonne/data"
+	"beeb/carcassonne/engine"
+	"math/rand"
+	"testing"
+)
+
+func TestRiverII_BuildDeck(t *testing.T) {
+	gameData := loadGameData(t, "../data/river_ii_deck.yml")
+
+	d := engine.RiverII{}.BuildDeck(gameData, rand.New(rand.NewSource(1)))
+
+	names := make([]string, 0, d.Remaining())
+
+	for d.Remaining() > 0 {
+		rtg, _ := d.Pop()
+		names = append(names, rtg.Name)
+	}
+
+	//the spring and the fork start the river, the lake ends it
+	if names[0] != "RiverTerminus" || names[1] != "RiverFork" || names[len(names)-1] != "RiverLake" {
+		t.Errorf("unexpected river order %v", names)
+	}
+
+	//the classic river leaves the river ii tiles out
+	d = engine.ClassicRiver{}.BuildDeck(gameData, rand.New(rand.NewSource(1)))
+
+	for d.Remaining() > 0 {
+		rtg, _ := d.Pop()
+
+		if rtg.Name == "RiverFork" || rtg.Name == "RiverLake" || rtg.Name == "RiverVolcano" {
+			t.Errorf("classic river deck has %s", rtg.Name)
+		}
+	}
+}
+
+func TestEngine_RiverVariants(t *testing.T) {
+	for _, river := range []string{data.NoRiver, data.ClassicRiver, data.RiverII} {
+		gameData := loadGameData(t, "../data/river_ii_deck.yml")
+		gameData.Rules.River = river
+
+		e := newEngine(t, gameData, 24, 3, 3)
+
+		riverTiles := 0
+
+		e.Subscribe(engine.EventSubscriberFunc(func(e *engine.Engine, event engine.Event) {
+			if drawn, ok := event.(engine.TileDrawnEvent); ok && drawn.Tile.IsRiverTile() {
+				riverTiles++
+			}
+		}))
+
+		for !e.GameOver {
+			if err := e.Step(); err != nil {
+				t.Fatalf("%s: %v", river, err)
+			}
+		}
+
+		if river == data.NoRiver && riverTiles > 0 {
+			t.Errorf("%s: %d river tiles drawn", river, riverTiles)
+		}
+
+		if river != data.NoRiver && riverTiles == 0 {
+			t.Errorf("%s: no river tiles drawn", river)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package engine_test
+
+import (
+	"beeb/carcassonne/data"
+	"beeb/carcassonne/engine/tile"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadRuleSet(t *testing.T) {
+	rules, err := data.LoadRuleSet("../data/standard_rules.yml")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(rules, data.DefaultRuleSet()) {
+		t.Errorf("standard rules file = %+v, want the default rules %+v", rules, data.DefaultRuleSet())
+	}
+
+}
+
+func TestLoadRuleSet_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules string
+		key   string
+	}{
+		{"unknown rule", "maxMeeple: 5\n", "maxMeeple"},
+		{"unknown feature type", "scores:\n  Moat: 4\n", "scores.Moat"},
+		{"negative score", "scores:\n  Road: -1\n", "scores.Road"},
+		{"negative end game score", "endGameScores:\n  Castle: -2\n", "endGameScores.Castle"},
+		{"negative bonus", "pigBonus: -1\n", "pigBonus"},
+		{"negative ransom", "ransom: -3\n", "ransom"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rulesPath := filepath.Join(t.TempDir(), "rules.yml")
+			_ = os.WriteFile(rulesPath, []byte(tt.rules), 0644)
+
+			_, err := data.LoadRuleSet(rulesPath)
+
+			var loadErr *data.LoadError
+			if !errors.As(err, &loadErr) || loadErr.Path != rulesPath || !errors.Is(err, data.ErrInvalidRuleSet) {
+				t.Fatalf("LoadRuleSet() error = %v, want %v loading %s", err, data.ErrInvalidRuleSet, rulesPath)
+			}
+
+			if !strings.Contains(err.Error(), tt.key) {
+				t.Errorf("LoadRuleSet() error = %v, expected it to name %s", err, tt.key)
+			}
+		})
+	}
+}
+
+func TestRuleSet_ValidateMissingScores(t *testing.T) {
+	tests := []struct {
+		name   string
+		remove func(rules *data.RuleSet)
+		key    string
+	}{
+		{"score", func(rules *data.RuleSet) { delete(rules.Scores, "Road") }, "scores.Road"},
+		{"end game score", func(rules *data.RuleSet) { delete(rules.EndGameScores, "Garden") }, "endGameScores.Garden"},
+		{"every score", func(rules *data.RuleSet) { rules.Scores = nil }, "scores.Farm"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := data.DefaultRuleSet()
+			tt.remove(rules)
+
+			err := rules.Validate()
+
+			if !errors.Is(err, data.ErrInvalidRuleSet) || !strings.Contains(err.Error(), tt.key) {
+				t.Errorf("Validate() error = %v, want %v naming %s", err, data.ErrInvalidRuleSet, tt.key)
+			}
+		})
+	}
+}
+
+func TestEngine_HouseRules(t *testing.T) {
+	gameData := loadGameData(t, "../data/standard_deck.yml")
+
+	rulesPath := filepath.Join(t.TempDir(), "house_rules.yml")
+	_ = os.WriteFile(rulesPath, []byte("maxMeeples: 2\nscores:\n  Road: 5\n"), 0644)
+
+	rules, err := data.LoadRuleSet(rulesPath)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//the rest of the rules are left as they are
+	if rules.DrawAttempts != 3 || rules.Score(tile.Castle) != 2 {
+		t.Errorf("rules left out of the file should keep their defaults, got %+v", rules)
+	}
+
+	e := newEngine(t, gameData, 16, 4, 8)
+	e.Rules = rules
+	e.InitGame()
+
+	for _, p := range e.Players {
+		if len(p.Meeples) != 2 {
+			t.Fatalf("player has %d meeples, the rules allow 2", len(p.Meeples))
+		}
+	}
+
+	roadsScored := 0
+
+	for !e.GameOver {
+		e.Step()
+
+		for _, fs := range e.FeaturesScoredThisTurn {
+			if fs.Type == tile.Road {
+				roadsScored++
+
+				if fs.Score != fs.Tiles*5 {
+					t.Errorf("road of %d tiles scored %d, the rules make it worth %d", fs.Tiles, fs.Score, fs.Tiles*5)
+				}
+			}
+		}
+	}
+
+	if roadsScored == 0 {
+		t.Error("expected some roads to be completed")
+	}
+}
@@ -0,0 +1,277 @@
+package engine
+
+import "beeb/carcassonne/engine/tile"
+
+// FeatureScore
+// the points a feature chain was worth when it was scored, and who was awarded them
+type FeatureScore struct {
+	Feature  *tile.Feature
+	Type     tile.FeatureType
+	Complete bool
+	Tiles    int
+	Score    int
+	Owners   []*Player
+
+	//points players got on top of the score, like for a pig in a field, or the fairy next to their follower
+	Bonuses map[*Player]int
+}
+
+// ScoreFinishedFeatures
+// finds every feature chain the tile placed this turn touches, and scores each one it completed.
+// the meeple placement decided by the player is not trusted here, as a single tile can complete
+// several features at once, including cloisters on the tiles around it
+func (e *Engine) ScoreFinishedFeatures() []FeatureScore {
+	t := e.TilePlacedThisTurn
+
+	if t == nil {
+		return nil
+	}
+
+	scores := make([]FeatureScore, 0, 4)
+	visitedFeatures := make(map[*tile.Feature]struct{})
+
+	scoreFeature := func(f *tile.Feature) {
+		if !scoredOnCompletion(f.Type) {
+			return
+		}
+
+		if _, exists := visitedFeatures[f]; exists {
+			return
+		}
+
+		featureChain := newFeatureChain(f, e.GameBoard, e.Rules)
+
+		for vf := range featureChain.FeaturesVisited {
+			visitedFeatures[vf] = struct{}{}
+		}
+
+		if !featureChain.isComplete {
+			return
+		}
+
+		featureChain.computeMeeples()
+		featureChain.computeScore()
+
+		if f.Type == tile.Castle {
+			e.collectGoods(&featureChain)
+		}
+
+		//nobody to score it for
+		if !featureChain.hasOwner() {
+			e.emit(FeatureCompletedEvent{
+				Turn:  e.TurnCounter,
+				Chain: &featureChain,
+				Score: newFeatureScore(&featureChain),
+			})
+
+			return
+		}
+
+		score := e.awardFeatureChain(&featureChain)
+		scores = append(scores, score)
+
+		e.emit(FeatureCompletedEvent{
+			Turn:  e.TurnCounter,
+			Chain: &featureChain,
+			Score: score,
+		})
+
+		e.emitMeeplesReturned(&featureChain)
+	}
+
+	for _, f := range t.Features {
+		scoreFeature(f)
+	}
+
+	//the tile may have surrounded a cloister or garden next to it
+	for _, st := range e.GameBoard.SurroundingTiles(t.Position) {
+		for _, f := range st.Features {
+			if f.Type == tile.Cloister || f.Type == tile.Garden {
+				scoreFeature(f)
+			}
+		}
+	}
+
+	return scores
+}
+
+// scoredOnCompletion
+// whether a feature of the type is scored as soon as it's finished. fields are only scored at the end of the game,
+// and markers like shields, inns and goods, or the river, are part of the feature they're on, not scored on their own
+func scoredOnCompletion(ft tile.FeatureType) bool {
+	switch ft {
+	case tile.Road, tile.Castle, tile.Cloister, tile.Garden:
+		return true
+	}
+
+	return false
+}
+
+// ScoreEndGame
+// walks every meeple still left on the board, and awards the owners of each feature chain
+// the points it's worth as it stands. each chain is only scored once, no matter how many meeples are on it.
+// the goods bonuses are awarded last
+func (e *Engine) ScoreEndGame() []FeatureScore {
+	scores := make([]FeatureScore, 0, 16)
+	scoredFeatures := make(map[*tile.Feature]struct{})
+
+	for _, p := range e.Players {
+		for _, m := range p.Meeples {
+			if m.Feature == nil {
+				continue
+			}
+
+			if _, exists := scoredFeatures[m.Feature]; exists {
+				continue
+			}
+
+			featureChain := newFeatureChain(m.Feature, e.GameBoard, e.Rules)
+
+			for f := range featureChain.FeaturesVisited {
+				scoredFeatures[f] = struct{}{}
+			}
+
+			featureChain.computeEndGameScore()
+			featureChain.computeMeeples()
+
+			scores = append(scores, e.awardFeatureChain(&featureChain))
+
+			e.emitMeeplesReturned(&featureChain)
+		}
+	}
+
+	return append(scores, e.scoreGoods()...)
+}
+
+// collectGoods
+// gives the goods in a finished castle to the player who finished it, whether or not they have a meeple in it
+func (e *Engine) collectGoods(featureChain *FeatureChain) {
+	player := e.CurrentPlayer()
+	goods := make(map[tile.FeatureType]int)
+
+	for _, goodsType := range tile.GoodsTypes() {
+		if count := featureChain.countMarkers(goodsType); count > 0 {
+			goods[goodsType] = count
+			player.Goods[goodsType] += count
+		}
+	}
+
+	if len(goods) < 1 {
+		return
+	}
+
+	e.emit(GoodsCollectedEvent{
+		Turn:   e.TurnCounter,
+		Player: player,
+		Chain:  featureChain,
+		Goods:  goods,
+	})
+}
+
+// scoreGoods
+// awards the goods bonus to the players with the most of each kind of goods at the end of the game, ties are all awarded it
+func (e *Engine) scoreGoods() []FeatureScore {
+	scores := make([]FeatureScore, 0, 3)
+
+	for _, goodsType := range tile.GoodsTypes() {
+		most := 0
+		for _, p := range e.Players {
+			if p.Goods[goodsType] > most {
+				most = p.Goods[goodsType]
+			}
+		}
+
+		if most < 1 {
+			continue
+		}
+
+		score := FeatureScore{
+			Type:     goodsType,
+			Complete: true,
+			Score:    e.Rules.GoodsBonus,
+			Owners:   make([]*Player, 0, 1),
+		}
+
+		for _, p := range e.Players {
+			if p.Goods[goodsType] == most {
+				p.Score += score.Score
+				score.Owners = append(score.Owners, p)
+			}
+		}
+
+		scores = append(scores, score)
+	}
+
+	return scores
+}
+
+// awardFeatureChain
+// gives the chain's score to the players with the most meeples on it, then returns all of its meeples.
+// the chain's score and meeples must have been computed already
+func (e *Engine) awardFeatureChain(featureChain *FeatureChain) FeatureScore {
+	featureChain.computePlayerMeeplesMap()
+	featureChain.computeOwners()
+
+	for _, owner := range featureChain.owners {
+		owner.Score += featureChain.score
+	}
+
+	score := newFeatureScore(featureChain)
+
+	if featureChain.Feature.Type == tile.Farm {
+		score.Bonuses = e.awardPigs(featureChain)
+	}
+
+	score.Bonuses = e.awardFairy(featureChain, score.Bonuses)
+
+	for _, m := range featureChain.meeples {
+		m.Detach()
+	}
+
+	return score
+}
+
+// awardPigs
+// owners of a field with their pig in it get the pig bonus for each finished castle the field borders
+func (e *Engine) awardPigs(featureChain *FeatureChain) map[*Player]int {
+	var bonuses map[*Player]int
+
+	for _, m := range featureChain.meeples {
+		if m.Type != Pig || !featureChain.isOwner(m.ParentPlayer) {
+			continue
+		}
+
+		if bonuses == nil {
+			bonuses = make(map[*Player]int, 1)
+		}
+
+		bonus := e.Rules.PigBonus * featureChain.completedAdjacentCastles()
+		bonuses[m.ParentPlayer] = bonus
+		m.ParentPlayer.Score += bonus
+	}
+
+	return bonuses
+}
+
+func newFeatureScore(featureChain *FeatureChain) FeatureScore {
+	return FeatureScore{
+		Feature:  featureChain.Feature,
+		Type:     featureChain.Feature.Type,
+		Complete: featureChain.isComplete,
+		Tiles:    len(featureChain.TilesVisited),
+		Score:    featureChain.score,
+		Owners:   featureChain.owners,
+	}
+}
+
+func (e *Engine) emitMeeplesReturned(featureChain *FeatureChain) {
+	if len(featureChain.meeples) < 1 {
+		return
+	}
+
+	e.emit(MeeplesReturnedEvent{
+		Turn:    e.TurnCounter,
+		Feature: featureChain.Feature,
+		Meeples: featureChain.meeples,
+	})
+}
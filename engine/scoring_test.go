@@ -0,0 +1,320 @@
+package engine_test
+
+import (
+	"beeb/carcassonne/data"
+	"beeb/carcassonne/engine"
+	"beeb/carcassonne/engine/tile"
+	"beeb/carcassonne/util"
+	"errors"
+	"testing"
+)
+
+func placeTestTile(e *engine.Engine, name string, orientation int, x int, y int) *tile.Tile {
+	return e.PlaceTile(engine.Placement{
+		Position:      util.Point[int]{X: x, Y: y},
+		ReferenceTile: e.GameData.ReferenceTileGroups[name].Orientations[orientation],
+	})
+}
+
+func placeTestMeeple(p *engine.Player, t *tile.Tile, featureType tile.FeatureType) *engine.Meeple {
+	m := p.GetAvailableMeepleWithPower(1)
+
+	for _, f := range t.Features {
+		if f.Type == featureType {
+			m.Feature = f
+			f.AttachedMeeples = append(f.AttachedMeeples, m)
+			return m
+		}
+	}
+
+	return nil
+}
+
+func attachTestMeeple(m *engine.Meeple, f *tile.Feature) {
+	m.Feature = f
+	f.AttachedMeeples = append(f.AttachedMeeples, m)
+}
+
+func featureOfType(t *tile.Tile, featureType tile.FeatureType) *tile.Feature {
+	for _, f := range t.Features {
+		if f.Type == featureType {
+			return f
+		}
+	}
+
+	return nil
+}
+
+func TestEngine_ScoreFinishedFeatures(t *testing.T) {
+	gameData := loadGameData(t, "../data/standard_deck.yml")
+	e := newEngine(t, gameData, 16, 2, 1)
+
+	p0, p1 := e.Players[0], e.Players[1]
+
+	//two castle end caps, facing each other across an empty space
+	top := placeTestTile(e, "CastleEndCap", 2, 5, 4)
+	bottom := placeTestTile(e, "CastleEndCap", 0, 5, 6)
+
+	m0 := placeTestMeeple(p0, top, tile.Castle)
+	m1 := placeTestMeeple(p1, bottom, tile.Castle)
+
+	//filling the space closes both castles at once
+	e.TilePlacedThisTurn = placeTestTile(e, "DoubleCastleEndCapNorthSouth", 0, 5, 5)
+	e.DecidedMeeplePlacementThisTurn = nil
+
+	scores := e.ScoreFinishedFeatures()
+
+	if len(scores) != 2 {
+		t.Fatalf("expected 2 finished features to be scored, got %d", len(scores))
+	}
+
+	if p0.Score != 4 || p1.Score != 4 {
+		t.Errorf("expected both players to score 4, got %d and %d", p0.Score, p1.Score)
+	}
+
+	if m0.Feature != nil || m1.Feature != nil {
+		t.Error("expected both meeples to be returned")
+	}
+}
+
+func TestEngine_ScoreEndGame(t *testing.T) {
+	gameData := loadGameData(t, "../data/standard_deck.yml")
+	e := newEngine(t, gameData, 16, 3, 1)
+
+	p0, p1, p2 := e.Players[0], e.Players[1], e.Players[2]
+
+	//a road over two tiles, open at both ends
+	placeTestMeeple(p0, placeTestTile(e, "RoadStraight", 0, 2, 8), tile.Road)
+	placeTestTile(e, "RoadStraight", 0, 3, 8)
+
+	//a castle with a shield, left open to the north
+	placeTestMeeple(p1, placeTestTile(e, "CastleCornerShield", 0, 2, 2), tile.Castle)
+	placeTestTile(e, "CastleEndCap", 3, 3, 2)
+
+	//a cloister with two of its eight neighbours
+	placeTestMeeple(p2, placeTestTile(e, "Cloister", 0, 8, 2), tile.Cloister)
+	placeTestTile(e, "Cloister", 0, 8, 1)
+	placeTestTile(e, "Cloister", 0, 8, 3)
+
+	expected := map[tile.FeatureType]int{
+		tile.Road:     2,
+		tile.Castle:   2 + 1,
+		tile.Cloister: 3,
+	}
+
+	scores := e.ScoreEndGame()
+
+	if len(scores) != len(expected) {
+		t.Fatalf("expected %d unfinished features to be scored, got %d", len(expected), len(scores))
+	}
+
+	for _, fs := range scores {
+		if fs.Complete || fs.Score != expected[fs.Type] {
+			t.Errorf("unfinished %s on %d tiles scored %d, want %d", fs.Type, fs.Tiles, fs.Score, expected[fs.Type])
+		}
+	}
+
+	if p0.Score != 2 || p1.Score != 3 || p2.Score != 3 {
+		t.Errorf("expected the players to score 2, 3 and 3, got %d, %d and %d", p0.Score, p1.Score, p2.Score)
+	}
+}
+
+func TestEngine_InnsAndCathedrals(t *testing.T) {
+	gameData := loadGameData(t, "../data/inns_and_cathedrals_deck.yml")
+
+	rules, err := data.LoadRuleSet("../data/inns_and_cathedrals_rules.yml")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gameData.Rules = rules
+	e := newEngine(t, gameData, 16, 2, 1)
+
+	p0, p1 := e.Players[0], e.Players[1]
+
+	bigMeeple := p1.GetAvailableMeepleWithPower(2)
+
+	if len(p1.Meeples) != 8 || bigMeeple == nil {
+		t.Fatalf("expected 7 meeples and a big meeple, got %d meeples", len(p1.Meeples))
+	}
+
+	//a road with an inn, between two junctions. the big meeple outweighs the normal one
+	west := placeTestTile(e, "RoadTerminal3", 0, 4, 5)
+	east := placeTestTile(e, "RoadTerminal3", 0, 6, 5)
+
+	attachTestMeeple(p0.GetAvailableMeepleWithPower(1), west.EdgeFeatures.GetEast())
+	attachTestMeeple(bigMeeple, east.EdgeFeatures.GetWest())
+
+	e.TilePlacedThisTurn = placeTestTile(e, "RoadStraightInn", 0, 5, 5)
+	scores := e.ScoreFinishedFeatures()
+
+	if len(scores) != 1 || len(scores[0].Owners) != 1 || scores[0].Owners[0] != p1 {
+		t.Fatalf("expected the road to be scored for the player with the big meeple, got %+v", scores)
+	}
+
+	if p0.Score != 0 || p1.Score != 6 {
+		t.Errorf("road with an inn scored %d and %d, want 0 and 6", p0.Score, p1.Score)
+	}
+
+	//a cathedral closed in on every side
+	cathedral := placeTestTile(e, "CastleFill4Cathedral", 0, 5, 8)
+	attachTestMeeple(p0.GetAvailableMeepleWithPower(1), cathedral.Features[0])
+
+	placeTestTile(e, "CastleEndCap", 2, 5, 7)
+	placeTestTile(e, "CastleEndCap", 1, 4, 8)
+	placeTestTile(e, "CastleEndCap", 3, 6, 8)
+	e.TilePlacedThisTurn = placeTestTile(e, "CastleEndCap", 0, 5, 9)
+
+	if e.ScoreFinishedFeatures(); p0.Score != 15 {
+		t.Errorf("finished castle with a cathedral scored %d, want 15", p0.Score)
+	}
+
+	//left unfinished, inns and cathedrals are worth nothing
+	attachTestMeeple(p0.GetAvailableMeepleWithPower(1), placeTestTile(e, "CastleFill4Cathedral", 0, 9, 8).Features[0])
+	attachTestMeeple(p1.GetAvailableMeepleWithPower(1), placeTestTile(e, "RoadStraightInn", 0, 9, 5).EdgeFeatures.GetEast())
+
+	for _, fs := range e.ScoreEndGame() {
+		if fs.Score != 0 {
+			t.Errorf("unfinished %s scored %d, want 0", fs.Type, fs.Score)
+		}
+	}
+}
+
+func TestEngine_TradersAndBuilders(t *testing.T) {
+	gameData := loadGameData(t, "../data/traders_and_builders_deck.yml")
+
+	rules, err := data.LoadRuleSet("../data/traders_and_builders_rules.yml")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gameData.Rules = rules
+	e := newEngine(t, gameData, 16, 2, 1)
+
+	p0, p1 := e.Players[0], e.Players[1]
+
+	//a castle with cloth in it, finished by the first player, though only the second has a meeple in it
+	west := placeTestTile(e, "CastleEndCap", 1, 4, 5)
+	placeTestTile(e, "CastleEndCap", 3, 6, 5)
+	attachTestMeeple(p1.GetAvailableMeeple(), west.Features[0])
+
+	e.CurrentPlayerIndex = 0
+	e.TilePlacedThisTurn = placeTestTile(e, "CastleLongCloth", 0, 5, 5)
+	e.ScoreFinishedFeatures()
+
+	if p0.Goods[tile.Cloth] != 1 || p1.Goods[tile.Cloth] != 0 || p1.Score != 6 {
+		t.Fatalf("expected the first player to collect the cloth and the second to score 6, got %v, %v and %d", p0.Goods, p1.Goods, p1.Score)
+	}
+
+	//a pig in the field beside the castle, with the player's follower
+	field := featureOfType(west, tile.Farm)
+	attachTestMeeple(p0.GetAvailableMeeple(), field)
+	attachTestMeeple(p0.GetAvailableMeepleOfType(engine.Pig), field)
+
+	scores := e.ScoreEndGame()
+
+	if p0.Score != 3+1+10 {
+		t.Errorf("expected the field, pig and cloth bonus to be worth 14, got %d", p0.Score)
+	}
+
+	if last := scores[len(scores)-1]; last.Type != tile.Cloth || last.Score != 10 || len(last.Owners) != 1 || last.Owners[0] != p0 {
+		t.Errorf("expected the cloth bonus to be scored last, got %+v", last)
+	}
+}
+
+func TestEngine_BuilderTurns(t *testing.T) {
+	gameData := loadGameData(t, "../data/traders_and_builders_deck.yml")
+
+	rules, err := data.LoadRuleSet("../data/traders_and_builders_rules.yml")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gameData.Rules = rules
+	e := newEngine(t, gameData, 16, 3, 1)
+
+	builderTurns := 0
+	lastBuilderTurn := -1
+
+	e.Subscribe(engine.EventSubscriberFunc(func(e *engine.Engine, event engine.Event) {
+		if tp, ok := event.(engine.TurnPassedEvent); ok && tp.Player == tp.PreviousPlayer {
+			if lastBuilderTurn == tp.Turn-1 {
+				t.Errorf("turn %d was a builder's extra turn, which can't give another", tp.Turn-1)
+			}
+
+			builderTurns++
+			lastBuilderTurn = tp.Turn
+		}
+	}))
+
+	for !e.GameOver {
+		if err := e.Step(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if builderTurns == 0 {
+		t.Error("expected the builders to give some extra turns")
+	}
+}
+
+func TestEngine_AbbotAndGardens(t *testing.T) {
+	gameData := loadGameData(t, "../data/abbot_deck.yml")
+
+	rules, err := data.LoadRuleSet("../data/abbot_rules.yml")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gameData.Rules = rules
+	e := newEngine(t, gameData, 16, 2, 1)
+
+	p0 := e.Players[0]
+	abbot := p0.GetAvailableMeepleOfType(engine.Abbot)
+
+	if abbot == nil {
+		t.Fatal("expected each player to have an abbot")
+	}
+
+	//only the abbot can go in a garden
+	e.CurrentPlayerIndex = 0
+	garden := placeTestTile(e, "Garden", 0, 5, 5)
+
+	mp := &engine.MeeplePlacement{
+		ParentFeature:  featureOfType(garden, tile.Garden).ParentFeature,
+		SelectedMeeple: p0.GetAvailableMeeple(),
+	}
+
+	if _, err := e.ValidateMeeplePlacement(garden, mp); !errors.Is(err, engine.ErrFeatureUnclaimable) {
+		t.Errorf("follower in a garden, error = %v, want %v", err, engine.ErrFeatureUnclaimable)
+	}
+
+	mp.SelectedMeeple = abbot
+
+	if _, err := e.ValidateMeeplePlacement(garden, mp); err != nil {
+		t.Fatalf("abbot in a garden, error = %v", err)
+	}
+
+	attachTestMeeple(abbot, featureOfType(garden, tile.Garden))
+
+	//recalled with two tiles beside the garden, it's worth the three tiles so far
+	placeTestTile(e, "Cloister", 0, 4, 5)
+	e.TilePlacedThisTurn = placeTestTile(e, "Cloister", 0, 6, 5)
+	e.DecidedMeeplePlacementThisTurn = &engine.MeeplePlacement{RecalledAbbot: abbot}
+
+	if err := e.PlaceMeepleOnFeature(); err != nil {
+		t.Fatal(err)
+	}
+
+	if abbot.Feature != nil || p0.Score != 3 {
+		t.Errorf("expected the abbot to be recalled for 3 points, got %d", p0.Score)
+	}
+
+	if err := e.PlaceMeepleOnFeature(); !errors.Is(err, engine.ErrRecallTarget) {
+		t.Errorf("recalling an abbot that isn't on the board, error = %v, want %v", err, engine.ErrRecallTarget)
+	}
+}
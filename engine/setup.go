@@ -0,0 +1,101 @@
+package engine
+
+import (
+	"beeb/carcassonne/data"
+	"fmt"
+	"image/color"
+)
+
+// validateSetup checks the setup, and that each player's AI is one of the built-in AIs
+func validateSetup(setup *data.GameSetup) error {
+	if err := setup.Validate(); err != nil {
+		return err
+	}
+
+	for i, ps := range setup.Players {
+		if _, exists := PlayerAITypes[ps.AI]; ps.AI != "" && !exists {
+			return fmt.Errorf("%w: player %d: unknown AI %s", data.ErrInvalidGameSetup, i, ps.AI)
+		}
+	}
+
+	return nil
+}
+
+// setupColors
+// each player's colour, the one the setup gives them, or the next colour from the palette which no one in the setup has chosen
+func setupColors(setup *data.GameSetup) []color.RGBA {
+	colors := make([]color.RGBA, len(setup.Players))
+	chosen := make(map[color.RGBA]struct{}, len(setup.Players))
+
+	for i, ps := range setup.Players {
+		if ps.Color != "" {
+			//the setup's already been validated
+			colors[i], _ = data.ParseColor(ps.Color)
+			chosen[colors[i]] = struct{}{}
+		}
+	}
+
+	//there are always enough palette colours left, even if everyone's chosen one which is in it
+	palette := make([]color.RGBA, 0, len(setup.Players))
+
+	for _, c := range PlayerColors(len(setup.Players) + len(chosen)) {
+		if _, exists := chosen[c]; !exists {
+			palette = append(palette, c)
+		}
+	}
+
+	for i, ps := range setup.Players {
+		if ps.Color == "" {
+			colors[i] = palette[0]
+			palette = palette[1:]
+		}
+	}
+
+	return colors
+}
+
+// newSetupPlayer
+// the player in the seat, in colour c, with their supply from the setup, or the rule set when the setup doesn't give one
+func (e *Engine) newSetupPlayer(seat int, ps data.PlayerSetup, c color.RGBA) *Player {
+	name := ps.Name
+
+	if name == "" {
+		name = fmt.Sprint("Player ", seat)
+	}
+
+	supply := e.Rules.Supply()
+
+	if ps.Meeples != nil {
+		supply = *ps.Meeples
+	}
+
+	p := NewPlayer(name, c, supply.Followers)
+	p.AddMeeples(supply.BigMeeples, Follower, 2)
+	p.AddMeeples(supply.Builders, Builder, 0)
+	p.AddMeeples(supply.Pigs, Pig, 0)
+	p.AddMeeples(supply.Abbots, Abbot, 1)
+	p.TowerFloors = supply.TowerFloors
+
+	if newAI, exists := PlayerAITypes[ps.AI]; exists {
+		p.AI = newAI(p)
+	}
+
+	return p
+}
+
+// setupFromPlayers
+// a setup for players who weren't set up from one, like those loaded from a state saved without it.
+// their supplies have been used up, so they come from the rules
+func setupFromPlayers(players []*Player) *data.GameSetup {
+	setup := data.DefaultGameSetup(len(players))
+
+	for i, p := range players {
+		setup.Players[i] = data.PlayerSetup{
+			Name:  p.Name,
+			Color: data.FormatColor(p.Color),
+			AI:    PlayerAITypeName(p.AI),
+		}
+	}
+
+	return setup
+}
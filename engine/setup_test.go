@@ -0,0 +1,141 @@
+package engine_test
+
+import (
+	"beeb/carcassonne/data"
+	"beeb/carcassonne/engine"
+	"errors"
+	"image/color"
+	"testing"
+)
+
+func TestEngine_Setup(t *testing.T) {
+	gameData := loadGameData(t, "../data/standard_deck.yml")
+
+	setup, err := data.LoadGameSetup("../data/eight_player_setup.yml")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e1, err := engine.NewEngineFromSetup(gameData, 24, setup, 2)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(e1.Players) != len(setup.Players) {
+		t.Fatalf("%d players, expected %d", len(e1.Players), len(setup.Players))
+	}
+
+	colors := make(map[color.RGBA]string)
+
+	for i, p := range e1.Players {
+		ps := setup.Players[i]
+
+		if p.Name != ps.Name || engine.PlayerAITypeName(p.AI) != ps.AI {
+			t.Errorf("player %d is %s with the %s AI, expected %s with the %s AI", i, p.Name, engine.PlayerAITypeName(p.AI), ps.Name, ps.AI)
+		}
+
+		c := color.RGBAModel.Convert(p.Color).(color.RGBA)
+
+		if other, exists := colors[c]; exists {
+			t.Errorf("%s has the same colour as %s", p.Name, other)
+		}
+
+		colors[c] = p.Name
+
+		if ps.Color != "" && data.FormatColor(c) != ps.Color {
+			t.Errorf("%s is %s, expected %s", p.Name, data.FormatColor(c), ps.Color)
+		}
+
+		meeples := gameData.Rules.MaxMeeples
+
+		if ps.Meeples != nil {
+			meeples = ps.Meeples.Followers + ps.Meeples.BigMeeples
+		}
+
+		if len(p.Meeples) != meeples {
+			t.Errorf("%s has %d meeples, expected %d", p.Name, len(p.Meeples), meeples)
+		}
+	}
+
+	for !e1.GameOver {
+		if err := e1.Step(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	//the players are set up the same way in a replay, and saved with the engine's state
+	e2, err := engine.Replay(gameData, e1.Record)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, p := range e1.Players {
+		if p2 := e2.Players[i]; p2.Name != p.Name || len(p2.Meeples) != len(p.Meeples) || p2.Score != p.Score {
+			t.Errorf("player %d is %s with %d meeples and %d points in the replay, expected %s with %d meeples and %d points",
+				i, p2.Name, len(p2.Meeples), p2.Score, p.Name, len(p.Meeples), p.Score)
+		}
+	}
+
+	e3, err := engine.LoadEngineState(gameData, engine.NewEngineState(e1))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e3.InitGame()
+
+	for i, p := range e1.Players {
+		if p3 := e3.Players[i]; p3.Name != p.Name || len(p3.Meeples) != len(p.Meeples) {
+			t.Errorf("player %d is %s with %d meeples in a restarted loaded game, expected %s with %d meeples", i, p3.Name, len(p3.Meeples), p.Name, len(p.Meeples))
+		}
+	}
+}
+
+func TestEngine_SetupColors(t *testing.T) {
+	gameData := loadGameData(t, "../data/standard_deck.yml")
+	palette := engine.PlayerColors(3)
+
+	//the third player has chosen the palette's second colour, so the second player gets the one after it
+	setup := &data.GameSetup{Players: []data.PlayerSetup{{}, {}, {Color: data.FormatColor(palette[1])}}}
+
+	e, err := engine.NewEngineFromSetup(gameData, 16, setup, 1)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, expected := range []color.Color{palette[0], palette[2], palette[1]} {
+		if c := data.FormatColor(e.Players[i].Color); c != data.FormatColor(expected) {
+			t.Errorf("player %d is %s, expected %s", i, c, data.FormatColor(expected))
+		}
+	}
+}
+
+func TestEngine_SetupErrors(t *testing.T) {
+	gameData := loadGameData(t, "../data/standard_deck.yml")
+
+	for _, ps := range []data.PlayerSetup{
+		{AI: "clairvoyant"},
+		{Color: "red"},
+		{Meeples: &data.MeepleSupply{Followers: -1}},
+	} {
+		setup := &data.GameSetup{Players: []data.PlayerSetup{{}, ps}}
+
+		if _, err := engine.NewEngineFromSetup(gameData, 16, setup, 1); !errors.Is(err, data.ErrInvalidGameSetup) {
+			t.Errorf("NewEngineFromSetup() with %+v, error = %v, want %v", ps, err, data.ErrInvalidGameSetup)
+		}
+	}
+
+	sameColors := &data.GameSetup{Players: []data.PlayerSetup{{Color: "#e53935"}, {Color: "#E53935"}}}
+
+	if _, err := engine.NewEngineFromSetup(gameData, 16, sameColors, 1); !errors.Is(err, data.ErrInvalidGameSetup) {
+		t.Errorf("NewEngineFromSetup() with two players the same colour, error = %v, want %v", err, data.ErrInvalidGameSetup)
+	}
+
+	if _, err := engine.NewEngineFromSetup(gameData, 16, &data.GameSetup{}, 1); !errors.Is(err, engine.ErrPlayerCount) {
+		t.Errorf("NewEngineFromSetup() with no players, error = %v, want %v", err, engine.ErrPlayerCount)
+	}
+}
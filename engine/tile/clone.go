@@ -0,0 +1,100 @@
+package tile
+
+// CloneMap
+// the copy made of each tile and feature while cloning, so anything else pointing at them can be pointed at the copies
+type CloneMap struct {
+	Tiles    map[*Tile]*Tile
+	Features map[*Feature]*Feature
+}
+
+// Tile gets the copy of t, or nil if t is nil
+func (cm *CloneMap) Tile(t *Tile) *Tile {
+	if t == nil {
+		return nil
+	}
+
+	return cm.Tiles[t]
+}
+
+// Feature gets the copy of f. features which weren't cloned, like the features of a reference tile, are returned as they are
+func (cm *CloneMap) Feature(f *Feature) *Feature {
+	if cf, exists := cm.Features[f]; exists {
+		return cf
+	}
+
+	return f
+}
+
+// CloneTiles
+// deep copies the tiles, their features, and the links between them.
+// the copies' attached meeples still point at the original meeples, as tiles don't know what meeples are
+func CloneTiles(tiles []*Tile) *CloneMap {
+	cm := &CloneMap{
+		Tiles:    make(map[*Tile]*Tile, len(tiles)),
+		Features: make(map[*Feature]*Feature, len(tiles)*4),
+	}
+
+	for _, t := range tiles {
+		cm.Tiles[t] = cm.cloneTile(t)
+	}
+
+	//every tile has been copied, so neighbours and links can be pointed at the copies
+	for t, ct := range cm.Tiles {
+		for i, n := range t.Neighbours {
+			ct.Neighbours[i] = cm.Tile(n)
+		}
+
+		for _, f := range t.Features {
+			cf := cm.Features[f]
+
+			for l := range f.Links {
+				cl := cm.Feature(l)
+				cf.Links[cl] = cl
+			}
+		}
+	}
+
+	return cm
+}
+
+func (cm *CloneMap) cloneTile(t *Tile) *Tile {
+	ct := &Tile{
+		Id:        t.Id,
+		Position:  t.Position,
+		Reference: t.Reference,
+	}
+
+	ct.Features = make([]*Feature, len(t.Features))
+	ct.EdgeFeatures = &EdgeArray[*Feature]{}
+	ct.Neighbours = &EdgeArray[*Tile]{}
+	ct.ReferenceFeatureMap = make(map[*Feature]*Feature, len(t.ReferenceFeatureMap))
+
+	for i, f := range t.Features {
+		cf := &Feature{
+			Id:                     f.Id,
+			Type:                   f.Type,
+			ParentTile:             ct,
+			ParentRefenceTileGroup: f.ParentRefenceTileGroup,
+			ParentFeature:          f.ParentFeature,
+			Links:                  make(map[*Feature]*Feature, len(f.Links)),
+		}
+
+		if len(f.AttachedMeeples) > 0 {
+			cf.AttachedMeeples = make([]interface{}, len(f.AttachedMeeples))
+			copy(cf.AttachedMeeples, f.AttachedMeeples)
+		}
+
+		cm.Features[f] = cf
+		ct.Features[i] = cf
+	}
+
+	for rf, f := range t.ReferenceFeatureMap {
+		ct.ReferenceFeatureMap[rf] = cm.Features[f]
+	}
+
+	for i, f := range t.EdgeFeatures {
+		ct.EdgeFeatures[i] = cm.Feature(f)
+	}
+
+	return ct
+}
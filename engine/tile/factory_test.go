@@ -8,7 +8,11 @@ import (
 )
 
 func TestTileFactory_NewTileFromReference(t *testing.T) {
-	gameData := data.LoadGameData("../data/bitmaps", "../data/standard_deck.yml")
+	gameData, err := data.LoadGameData("../data/bitmaps", "../data/standard_deck.yml")
+
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	referenceTile := gameData.ReferenceTileGroups["CloisterRiverRoad"]
 	orientedReferenceTile := referenceTile.Orientations[0]
@@ -21,7 +25,11 @@ func TestTileFactory_NewTileFromReference(t *testing.T) {
 }
 
 func BenchmarkTileFactory_NewTileFromReference(b *testing.B) {
-	gameData := data.LoadGameData("../data/bitmaps", "../data/standard_deck.yml")
+	gameData, err := data.LoadGameData("../data/bitmaps", "../data/standard_deck.yml")
+
+	if err != nil {
+		b.Fatal(err)
+	}
 
 	referenceTile := gameData.ReferenceTileGroups["CloisterRiverRoad"]
 	orientedReferenceTile := referenceTile.Orientations[0]
@@ -14,16 +14,60 @@ var featureTypeStrMap []string = []string{
 	"Cloister",
 	"River",
 	"Shield",
+	"Inn",
+	"Cathedral",
+	"Wine",
+	"Grain",
+	"Cloth",
+	"Volcano",
+	"Dragon",
+	"Princess",
+	"Portal",
+	"Garden",
+	"Tower",
 }
 
 var featureTypeScoreMap []int = []int{
 	0, //"None",
-	0, //"Farm",
+	3, //"Farm",
 	1, //"Road",
 	2, //"Castle",
-	0, //"Cloister",
+	1, //"Cloister",
 	0, //"River",
 	2, //"Shield",
+	2, //"Inn",
+	3, //"Cathedral",
+	0, //"Wine",
+	0, //"Grain",
+	0, //"Cloth",
+	0, //"Volcano",
+	0, //"Dragon",
+	0, //"Princess",
+	0, //"Portal",
+	1, //"Garden",
+	0, //"Tower",
+}
+
+// features which are unfinished at the end of the game are worth less
+var featureTypeEndGameScoreMap []int = []int{
+	0, //"None",
+	3, //"Farm",
+	1, //"Road",
+	1, //"Castle",
+	1, //"Cloister",
+	0, //"River",
+	1, //"Shield",
+	0, //"Inn",
+	0, //"Cathedral",
+	0, //"Wine",
+	0, //"Grain",
+	0, //"Cloth",
+	0, //"Volcano",
+	0, //"Dragon",
+	0, //"Princess",
+	0, //"Portal",
+	1, //"Garden",
+	0, //"Tower",
 }
 
 const (
@@ -34,16 +78,79 @@ const (
 	Cloister
 	River
 	Shield
+
+	//inns and cathedrals are markers, like shields. they change what the road or castle they're on is worth,
+	//their score is what each tile of that road or castle is worth instead
+	Inn
+	Cathedral
+
+	//goods are markers inside castles, they're collected by whoever finishes the castle rather than scored
+	Wine
+	Grain
+	Cloth
+
+	//volcanoes, dragons and magic portals are markers which change what happens when the tile is placed,
+	//the princess is a marker inside a castle, she lets the player who places her send a follower in the castle home
+	Volcano
+	Dragon
+	Princess
+	Portal
+
+	//a garden is scored like a cloister, by the tiles around it, but only an abbot can be placed in it
+	Garden
+
+	//a tower foundation is a marker which players build tower floors on, instead of placing a meeple
+	Tower
 )
 
 func (ft FeatureType) String() string {
 	return featureTypeStrMap[ft]
 }
 
+// ParseFeatureType finds the feature type with the given name, the opposite of String
+func ParseFeatureType(name string) (FeatureType, bool) {
+	for i, str := range featureTypeStrMap {
+		if str == name {
+			return FeatureType(i), true
+		}
+	}
+
+	return None, false
+}
+
+// FeatureTypes every feature type, in order
+func FeatureTypes() []FeatureType {
+	featureTypes := make([]FeatureType, len(featureTypeStrMap))
+
+	for i := range featureTypes {
+		featureTypes[i] = FeatureType(i)
+	}
+
+	return featureTypes
+}
+
+// GoodsTypes the kinds of goods which can be collected, in order
+func GoodsTypes() []FeatureType {
+	return []FeatureType{Wine, Grain, Cloth}
+}
+
+func (ft FeatureType) IsGoods() bool {
+	switch ft {
+	case Wine, Grain, Cloth:
+		return true
+	}
+
+	return false
+}
+
 func (ft FeatureType) Score() int {
 	return featureTypeScoreMap[ft]
 }
 
+func (ft FeatureType) EndGameScore() int {
+	return featureTypeEndGameScoreMap[ft]
+}
+
 type Feature struct {
 	Id                     uuid.UUID
 	Type                   FeatureType
@@ -56,6 +163,17 @@ type Feature struct {
 	AttachedMeeples []interface{}
 }
 
+// Claimable
+// whether a meeple can be placed on this type of feature
+func (ft FeatureType) Claimable() bool {
+	switch ft {
+	case Farm, Road, Castle, Cloister:
+		return true
+	}
+
+	return false
+}
+
 func (f *Feature) String() string {
 	return f.Type.String()
 }
@@ -24,6 +24,8 @@ type ReferenceTile struct {
 	EdgeFeatures  *EdgeArray[*Feature]
 	EdgeSignature *EdgeSignature
 	AvgFeaturePos map[*Feature]util.Point[float64]
+	//features which border each other on the tile, like a farm and the castle walls next to it
+	AdjacentFeatures map[*Feature][]*Feature
 }
 
 type Tile struct {
@@ -45,6 +47,17 @@ func (rtg *ReferenceTileGroup) IsRiverTerminus() bool {
 	return rtg.Name == "RiverTerminus"
 }
 
+// HasFeatureType whether the tile has a feature of the given type anywhere on it, like a volcano
+func (rt *ReferenceTile) HasFeatureType(featureType FeatureType) bool {
+	for _, f := range rt.Features {
+		if f.Type == featureType {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (t *Tile) HasFeature(f *Feature) bool {
 	for _, tf := range t.Features {
 		if tf == f {
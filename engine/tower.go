@@ -0,0 +1,213 @@
+package engine
+
+import (
+	"beeb/carcassonne/engine/tile"
+	"beeb/carcassonne/util"
+)
+
+// towerHeight
+// how many floors have been built on the tower at the position, if there's a tower there.
+// t is the tile placed this turn, its foundation counts as a tower before the tile's been through the engine's bookkeeping
+func (e *Engine) towerHeight(t *tile.Tile, pos util.Point[int]) (int, bool) {
+	if height, exists := e.TowerHeights[pos]; exists {
+		return height, true
+	}
+
+	if t != nil && t.Position == pos && t.Reference.HasFeatureType(tile.Tower) {
+		return 0, true
+	}
+
+	return 0, false
+}
+
+// towerReach
+// the meeples on the board a tower of the given height could capture, those on its own tile,
+// or on a tile as many tiles away as the tower is high, in the same row or column
+func (e *Engine) towerReach(pos util.Point[int], height int) []*Meeple {
+	meeples := make([]*Meeple, 0, 4)
+
+	for _, p := range e.Players {
+		for _, m := range p.Meeples {
+			if m.Feature == nil {
+				continue
+			}
+
+			diff := m.Feature.ParentTile.Position.Subtract(pos)
+
+			if diff.X != 0 && diff.Y != 0 {
+				continue
+			}
+
+			if diff.X <= height && diff.X >= -height && diff.Y <= height && diff.Y >= -height {
+				meeples = append(meeples, m)
+			}
+		}
+	}
+
+	return meeples
+}
+
+// buildTower
+// builds a floor on a tower instead of the player placing a meeple, and captures the meeple they picked in its new reach
+func (e *Engine) buildTower(t *tile.Tile, mp *MeeplePlacement) error {
+	if err := e.validateTower(t, mp); err != nil {
+		return err
+	}
+
+	player := e.CurrentPlayer()
+	pos := *mp.TowerPosition
+
+	height, _ := e.towerHeight(t, pos)
+	height++
+
+	e.TowerHeights[pos] = height
+	player.TowerFloors--
+
+	if m := mp.CapturedMeeple; m != nil {
+		m.Detach()
+
+		if e.FairyMeeple == m {
+			e.FairyMeeple = nil
+		}
+
+		m.Captor = player
+		player.Prisoners = append(player.Prisoners, m)
+	}
+
+	e.emit(TowerBuiltEvent{
+		Turn:     e.TurnCounter,
+		Player:   player,
+		Position: pos,
+		Height:   height,
+		Captured: mp.CapturedMeeple,
+	})
+
+	return nil
+}
+
+func (e *Engine) validateTower(t *tile.Tile, mp *MeeplePlacement) error {
+	player := e.CurrentPlayer()
+
+	invalid := func(err error) error {
+		return &InvalidMeeplePlacementError{
+			Player:    player,
+			Placement: mp,
+			Err:       err,
+		}
+	}
+
+	if mp.TowerPosition == nil {
+		return invalid(ErrNoTower)
+	}
+
+	height, exists := e.towerHeight(t, *mp.TowerPosition)
+
+	if !exists {
+		return invalid(ErrNoTower)
+	}
+
+	if player.TowerFloors < 1 {
+		return invalid(ErrNoTowerFloors)
+	}
+
+	m := mp.CapturedMeeple
+
+	if m == nil {
+		return nil
+	}
+
+	if m.ParentPlayer == player {
+		return invalid(ErrCaptureTarget)
+	}
+
+	for _, rm := range e.towerReach(*mp.TowerPosition, height+1) {
+		if rm == m {
+			return nil
+		}
+	}
+
+	return invalid(ErrCaptureTarget)
+}
+
+type towerCapture struct {
+	position util.Point[int]
+	meeple   *Meeple
+}
+
+// legalTowerCaptures
+// each tower the current player could build a floor on after the placement, including a foundation on the placement's tile,
+// with each meeple it could capture, or no meeple
+func (e *Engine) legalTowerCaptures(placement Placement) []towerCapture {
+	if e.CurrentPlayer().TowerFloors < 1 {
+		return nil
+	}
+
+	captures := make([]towerCapture, 0, len(e.TowerHeights)+1)
+
+	e.withHypotheticalTile(placement, func(t *tile.Tile) {
+		positions := make([]util.Point[int], 0, len(e.TowerHeights)+1)
+
+		for _, bt := range e.GameBoard.PlacedTiles() {
+			if _, exists := e.towerHeight(t, bt.Position); exists {
+				positions = append(positions, bt.Position)
+			}
+		}
+
+		for _, pos := range positions {
+			captures = append(captures, towerCapture{position: pos})
+
+			for _, m := range e.legalTowerMeeples(t, pos) {
+				captures = append(captures, towerCapture{position: pos, meeple: m})
+			}
+		}
+	})
+
+	return captures
+}
+
+// the other players' meeples the tower at the position could capture once it's a floor higher
+func (e *Engine) legalTowerMeeples(t *tile.Tile, pos util.Point[int]) []*Meeple {
+	height, _ := e.towerHeight(t, pos)
+	meeples := make([]*Meeple, 0, 2)
+
+	for _, m := range e.towerReach(pos, height+1) {
+		if m.ParentPlayer != e.CurrentPlayer() {
+			meeples = append(meeples, m)
+		}
+	}
+
+	return meeples
+}
+
+// ransomPrisoners
+// the current player buys back one of their captured meeples at the start of their turn, if they can afford the ransom.
+// the ransom goes to the player who was holding it
+func (e *Engine) ransomPrisoners() {
+	player := e.CurrentPlayer()
+
+	if player.Score < e.Rules.Ransom {
+		return
+	}
+
+	for _, captor := range e.Players {
+		for _, m := range captor.Prisoners {
+			if m.ParentPlayer != player {
+				continue
+			}
+
+			player.Score -= e.Rules.Ransom
+			captor.Score += e.Rules.Ransom
+			captor.releasePrisoner(m)
+
+			e.emit(MeepleRansomedEvent{
+				Turn:   e.TurnCounter,
+				Player: player,
+				Captor: captor,
+				Meeple: m,
+				Ransom: e.Rules.Ransom,
+			})
+
+			return
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package engine_test
+
+import (
+	"beeb/carcassonne/data"
+	"beeb/carcassonne/engine"
+	"reflect"
+	"testing"
+)
+
+func TestEngine_Tower(t *testing.T) {
+	gameData := loadGameData(t, "../data/tower_deck.yml")
+
+	rules, err := data.LoadRuleSet("../data/tower_rules.yml")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gameData.Rules = rules
+	e1 := newEngine(t, gameData, 24, 3, 0)
+
+	captured := 0
+	ransomed := 0
+
+	e1.Subscribe(engine.EventSubscriberFunc(func(e *engine.Engine, event engine.Event) {
+		switch event := event.(type) {
+		case engine.TowerBuiltEvent:
+			if e.TowerHeights[event.Position] != event.Height {
+				t.Errorf("turn %d: tower at %v is %d high, expected %d", event.Turn, event.Position, e.TowerHeights[event.Position], event.Height)
+			}
+
+			if m := event.Captured; m != nil {
+				captured++
+
+				if m.Feature != nil || m.Captor != event.Player {
+					t.Errorf("turn %d: captured meeple is still on the board, or isn't held by the tower's builder", event.Turn)
+				}
+			}
+		case engine.MeepleRansomedEvent:
+			ransomed++
+
+			if event.Meeple.Captor != nil {
+				t.Errorf("turn %d: ransomed meeple is still held prisoner", event.Turn)
+			}
+		}
+	}))
+
+	for !e1.GameOver {
+		if err := e1.Step(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if captured == 0 || ransomed == 0 {
+		t.Fatal("expected towers to capture meeples, and them to be ransomed")
+	}
+
+	//every floor built comes out of a player's supply
+	floors := 0
+
+	for _, height := range e1.TowerHeights {
+		floors += height
+	}
+
+	for _, p := range e1.Players {
+		floors += p.TowerFloors
+	}
+
+	if floors != len(e1.Players)*rules.TowerFloors {
+		t.Errorf("%d tower floors in the game, expected %d", floors, len(e1.Players)*rules.TowerFloors)
+	}
+
+	//the towers are built the same way in a replay, and saved with the engine's state
+	e2, err := engine.Replay(gameData, e1.Record)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(e2.TowerHeights, e1.TowerHeights) {
+		t.Errorf("towers are %v in the replay, expected %v", e2.TowerHeights, e1.TowerHeights)
+	}
+
+	for i, p := range e1.Players {
+		if e2.Players[i].Score != p.Score {
+			t.Errorf("player %d scored %d in the replay, expected %d", i, e2.Players[i].Score, p.Score)
+		}
+	}
+
+	e3, err := engine.LoadEngineState(gameData, engine.NewEngineState(e1))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(engine.NewEngineState(e3).Towers, engine.NewEngineState(e1).Towers) {
+		t.Error("loaded towers do not match the saved state")
+	}
+}
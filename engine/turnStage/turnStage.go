@@ -6,6 +6,7 @@ const (
 	Draw        TurnStage = 0
 	PlaceTile   TurnStage = 1
 	PlaceMeeple TurnStage = 2
-	Score       TurnStage = 3
-	Pass        TurnStage = 4
+	MoveDragon  TurnStage = 3
+	Score       TurnStage = 4
+	Pass        TurnStage = 5
 )
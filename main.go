@@ -5,38 +5,85 @@ import (
 	"beeb/carcassonne/data"
 	"beeb/carcassonne/engine"
 	"beeb/carcassonne/simulator"
-	"math/rand"
+	"flag"
 )
 
+var setupFilePath = flag.String("setup", "./data/eight_player_setup.yml", "the players for the game, in a yaml game setup file")
+
 func main() {
+	flag.Parse()
+
 	runAILink()
 	runSimulator()
 	// runExplorer()
 }
 
 func runAILink() {
-	rand.Seed(1)
-	gameData := data.LoadGameData("./data/bitmaps", "./data/custom_deck.yml")
-	engineInstance := engine.NewEngine(gameData, 16, 4)
+	gameData, err := data.LoadGameData("./data/bitmaps", "./data/custom_deck.yml")
+
+	if err != nil {
+		panic(err)
+	}
+
+	setup, err := data.LoadGameSetup(*setupFilePath)
+
+	if err != nil {
+		panic(err)
+	}
+
+	engineInstance, err := engine.NewEngineFromSetup(gameData, 16, setup, 1)
+
+	if err != nil {
+		panic(err)
+	}
+
 	ai := aiLink.NewAILink(engineInstance)
 	steps := (engineInstance.RiverDeck.Remaining() + engineInstance.Deck.Remaining()) * 5
 	for i := 0; i < steps; i++ {
-		engineInstance.Step()
+		if err := engineInstance.Step(); err != nil {
+			panic(err)
+		}
 	}
 
 	ai.Inputs()
 }
 
 func runSimulator() {
-	rand.Seed(1)
-	gameData := data.LoadGameData("./data/bitmaps", "./data/standard_deck.yml")
-	engineInstance := engine.NewEngine(gameData, 16, 4)
+	gameData, err := data.LoadGameData("./data/bitmaps", "./data/standard_deck.yml")
+
+	if err != nil {
+		panic(err)
+	}
+
+	rules, err := data.LoadRuleSet("./data/standard_rules.yml")
+
+	if err != nil {
+		panic(err)
+	}
+
+	gameData.Rules = rules
+	setup, err := data.LoadGameSetup(*setupFilePath)
+
+	if err != nil {
+		panic(err)
+	}
+
+	engineInstance, err := engine.NewEngineFromSetup(gameData, 16, setup, 1)
+
+	if err != nil {
+		panic(err)
+	}
+
 	sim := simulator.NewSimulator(engineInstance)
 	sim.Simulate()
 }
 
 func runExplorer() {
-	gameData := data.LoadGameData("./data/bitmaps", "./data/standard_deck.yml")
+	gameData, err := data.LoadGameData("./data/bitmaps", "./data/standard_deck.yml")
+
+	if err != nil {
+		panic(err)
+	}
 
 	gameData.Explore()
 }
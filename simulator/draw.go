@@ -41,6 +41,10 @@ type DrawData struct {
 	hdScale      int
 	cameraOffset util.Point[int]
 
+	//the board position of the board image's top left tile, and its width in tiles
+	boardOrigin util.Point[int]
+	boardTiles  int
+
 	redrawBoard bool
 
 	blackShader          *ebiten.Shader
@@ -56,10 +60,8 @@ func (sim *Simulator) initDraw() {
 	sim.drawData.hdScale = 8
 	sim.drawData.cameraOffset = util.Point[int]{}
 
-	boardPxSize := sim.Engine.GameBoard.TileMatrix.Size() * TILE_SIZE
-	sim.drawData.boardImage = ebiten.NewImage(boardPxSize, boardPxSize)
-	sim.drawData.possibleTilePlacementsImage = ebiten.NewImage(boardPxSize, boardPxSize)
-	sim.drawData.overlayImg = ebiten.NewImage(boardPxSize*sim.drawData.hdScale, boardPxSize*sim.drawData.hdScale)
+	boardMatrix, _ := sim.Engine.GameBoard.Matrix()
+	sim.resizeBoardImages(boardMatrix.Size())
 
 	sim.drawData.deckImage = ebiten.NewImage(TILE_SIZE, TILE_SIZE)
 	sim.drawData.riverDeckImage = ebiten.NewImage(TILE_SIZE, TILE_SIZE)
@@ -76,6 +78,27 @@ func (sim *Simulator) initDraw() {
 	sim.drawData.colorShader = loadShader("./simulator/shaders/color.kage")
 }
 
+// resizeBoardImages
+// an unbounded board grows as tiles are placed, so the images it's drawn to have to grow with it
+func (sim *Simulator) resizeBoardImages(boardTiles int) {
+	//ebiten can't make empty images
+	if boardTiles < 1 {
+		boardTiles = 1
+	}
+
+	sim.drawData.boardTiles = boardTiles
+
+	boardPxSize := boardTiles * TILE_SIZE
+	sim.drawData.boardImage = ebiten.NewImage(boardPxSize, boardPxSize)
+	sim.drawData.possibleTilePlacementsImage = ebiten.NewImage(boardPxSize, boardPxSize)
+	sim.drawData.overlayImg = ebiten.NewImage(boardPxSize*sim.drawData.hdScale, boardPxSize*sim.drawData.hdScale)
+}
+
+// toImageSpace converts a position on the board to a tile position on the board image
+func (sim *Simulator) toImageSpace(boardPoint util.Point[int]) util.Point[int] {
+	return boardPoint.Subtract(sim.drawData.boardOrigin)
+}
+
 func (sim *Simulator) Draw(screen *ebiten.Image) {
 	screen.Fill(colornames.Grey200)
 
@@ -121,7 +144,7 @@ func (sim *Simulator) toBoardSpace(screenSpacePoint util.Point[int]) util.Point[
 		X: int(worldSpacePoint.X / (sim.drawData.boardScale * sim.drawData.scale * float64(TILE_SIZE))),
 		Y: int(worldSpacePoint.Y / (sim.drawData.boardScale * sim.drawData.scale * float64(TILE_SIZE))),
 	}
-	return boardSpacePoint
+	return boardSpacePoint.Add(sim.drawData.boardOrigin)
 }
 
 func loadImage(fileName string) *ebiten.Image {
@@ -157,14 +180,21 @@ func loadShader(fileName string) *ebiten.Shader {
 }
 
 func (sim *Simulator) drawBoard() {
+	boardMatrix, origin := sim.Engine.GameBoard.Matrix()
+	sim.drawData.boardOrigin = origin
+
+	if boardMatrix.Size() > sim.drawData.boardTiles {
+		sim.resizeBoardImages(boardMatrix.Size())
+	}
+
 	sim.drawData.boardImage.Clear()
 
-	boardSize := sim.Engine.GameBoard.TileMatrix.Size()
+	boardSize := boardMatrix.Size()
 	op := ebiten.DrawImageOptions{}
 
 	for y := 0; y < boardSize; y++ {
 		for x := 0; x < boardSize; x++ {
-			tile := sim.Engine.GameBoard.TileMatrix.Get(x, y)
+			tile := boardMatrix.Get(x, y)
 			tx, ty := float64(x*TILE_SIZE), float64(y*TILE_SIZE)
 			op.GeoM.Translate(tx, ty)
 
@@ -189,6 +219,7 @@ func (sim *Simulator) drawOpenPositions() {
 
 	for _, pt := range sim.Engine.GameBoard.OpenPositionsList() {
 
+		pt = sim.toImageSpace(pt)
 		x := pt.X
 		y := pt.Y
 
@@ -217,12 +248,13 @@ func (sim *Simulator) drawFeatureLinks() {
 
 	drawnFeatures := make(map[*tile.Feature]*tile.Feature)
 
-	boardSize := sim.Engine.GameBoard.TileMatrix.Size()
+	boardMatrix, _ := sim.Engine.GameBoard.Matrix()
+	boardSize := boardMatrix.Size()
 	s := float64(sim.drawData.hdScale)
 	var m float32 = 2
 	for y := 0; y < boardSize; y++ {
 		for x := 0; x < boardSize; x++ {
-			t := sim.Engine.GameBoard.TileMatrix.Get(x, y)
+			t := boardMatrix.Get(x, y)
 			tx, ty := float32(float64(x*TILE_SIZE)*s), float32(float64(y*TILE_SIZE)*s)
 
 			if t != nil {
@@ -244,7 +276,7 @@ func (sim *Simulator) drawFeatureLinks() {
 						lfRefTile := lf.ParentTile.Reference
 						lfAvgPos := lfRefTile.AvgFeaturePos[lf.ParentFeature]
 
-						lfPos := lf.ParentTile.Position
+						lfPos := sim.toImageSpace(lf.ParentTile.Position)
 						lfTx, lfTy := float32(float64(lfPos.X*TILE_SIZE)*s), float32(float64(lfPos.Y*TILE_SIZE)*s)
 						lfaX := lfTx + float32(lfAvgPos.X*s)
 						lfaY := lfTy + float32(lfAvgPos.Y*s)
@@ -283,15 +315,21 @@ func (sim *Simulator) drawMeeples() {
 				continue
 			}
 
-			var meepleScale float32 = float32(s) * 2
+			//big meeples are drawn bigger, builders and pigs smaller
+			var meepleScale float32 = float32(s) * 2 * float32(m.Power)
+
+			if !m.Type.Claims() {
+				meepleScale = float32(s)
+			}
 
 			f := m.Feature
 			t := f.ParentTile
 
 			avg := t.Reference.AvgFeaturePos[f.ParentFeature]
 
-			x := float32(t.Position.X*TILE_SIZE) * s
-			y := float32(t.Position.Y*TILE_SIZE) * s
+			pos := sim.toImageSpace(t.Position)
+			x := float32(pos.X*TILE_SIZE) * s
+			y := float32(pos.Y*TILE_SIZE) * s
 
 			x += float32(avg.X) * s
 			y += float32(avg.Y) * s
@@ -317,11 +355,62 @@ func (sim *Simulator) drawMeeples() {
 
 }
 
+// drawDragonAndFairy
+// the dragon is drawn in the top left corner of its tile, and the fairy in the top right of hers
+func (sim *Simulator) drawDragonAndFairy() {
+	if p := sim.Engine.DragonPosition; p != nil {
+		sim.drawTileMarker(*p, 1, colornames.Red900)
+	}
+
+	if p := sim.Engine.FairyPosition; p != nil {
+		sim.drawTileMarker(*p, float32(TILE_SIZE)-1, colornames.Pink200)
+	}
+}
+
+// drawTowers
+// towers with floors built on them are marked in the top middle of their tile
+func (sim *Simulator) drawTowers() {
+	for pos, height := range sim.Engine.TowerHeights {
+		if height > 0 {
+			sim.drawTileMarker(pos, float32(TILE_SIZE)/2, colornames.Grey600)
+		}
+	}
+}
+
+func (sim *Simulator) drawTileMarker(boardPos util.Point[int], offsetX float32, c color.Color) {
+	s := float32(sim.drawData.hdScale)
+
+	var path vector.Path
+
+	pos := sim.toImageSpace(boardPos)
+	x := (float32(pos.X*TILE_SIZE) + offsetX) * s
+	y := (float32(pos.Y*TILE_SIZE) + 1) * s
+
+	path.MoveTo(x, y-s)
+	path.LineTo(x+s, y)
+	path.LineTo(x, y+s)
+	path.LineTo(x-s, y)
+	path.LineTo(x, y-s)
+
+	op := &ebiten.DrawTrianglesShaderOptions{
+		FillRule: ebiten.FillAll,
+	}
+
+	op.Uniforms = make(map[string]interface{})
+	r, g, b, a := c.RGBA()
+	op.Uniforms["RGBA"] = []float32{float32(r) / 65535, float32(g) / 65535, float32(b) / 65535, float32(a) / 65535}
+
+	vs, is := path.AppendVerticesAndIndicesForFilling(nil, nil)
+	sim.drawData.overlayImg.DrawTrianglesShader(vs, is, sim.drawData.colorShader, op)
+}
+
 func (sim *Simulator) drawOverlay() {
 	sim.drawData.overlayImg.Clear()
 	//sim.drawOpenPositions()
 	//sim.drawFeatureLinks()
 	sim.drawMeeples()
+	sim.drawDragonAndFairy()
+	sim.drawTowers()
 }
 
 func (sim *Simulator) drawPossibleTilePlacements() {
@@ -330,7 +419,8 @@ func (sim *Simulator) drawPossibleTilePlacements() {
 	op := ebiten.DrawImageOptions{}
 
 	for _, placement := range sim.Engine.CurrentPossibleTilePlacements {
-		tx, ty := float64(placement.Position.X*TILE_SIZE), float64(placement.Position.Y*TILE_SIZE)
+		pos := sim.toImageSpace(placement.Position)
+		tx, ty := float64(pos.X*TILE_SIZE), float64(pos.Y*TILE_SIZE)
 		op.GeoM.Translate(tx, ty)
 
 		sim.drawData.possibleTilePlacementsImage.DrawImage(sim.drawData.lightTileBackImage, &op)
@@ -22,9 +22,23 @@ func NewSimulator(engine *engine.Engine) *Simulator {
 	sim.playSpeed = 0 * time.Millisecond
 	sim.initDraw()
 
+	sim.subscribeToEngine()
+
 	return sim
 }
 
+func (sim *Simulator) subscribeToEngine() {
+	sim.Engine.Subscribe(engine.EventSubscriberFunc(sim.onEngineEvent))
+}
+
+// onEngineEvent redraws the board whenever something on it changes
+func (sim *Simulator) onEngineEvent(e *engine.Engine, event engine.Event) {
+	switch event.(type) {
+	case engine.TilePlacedEvent, engine.MeeplePlacedEvent, engine.MeeplesReturnedEvent, engine.GameOverEvent:
+		sim.drawData.redrawBoard = true
+	}
+}
+
 func (sim *Simulator) Simulate() {
 	ebiten.SetWindowSize(1200, 900)
 	ebiten.SetWindowTitle("Carcassonne Simulator")
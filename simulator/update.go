@@ -1,7 +1,6 @@
 package simulator
 
 import (
-	"beeb/carcassonne/engine/turnStage"
 	"beeb/carcassonne/util"
 	"fmt"
 	"time"
@@ -55,18 +54,9 @@ func (sim *Simulator) Update() error {
 		steps := (sim.Engine.RiverDeck.Remaining() + sim.Engine.Deck.Remaining()) * 5 // steps in game
 		//steps := 5 // steps per turn
 		for i := 0; i < steps; i++ {
-			stage := sim.Engine.TurnStage
-
-			sim.Engine.Step()
-
-			if stage == turnStage.PlaceTile || stage == turnStage.PlaceMeeple {
-				sim.drawData.redrawBoard = true
+			if err := sim.Engine.Step(); err != nil {
+				return err
 			}
-
-			if sim.Engine.GameOver {
-				sim.drawData.redrawBoard = true
-			}
-
 		}
 
 		rMouseDown = true
@@ -91,9 +81,9 @@ func (sim *Simulator) GetObjectUnderCursor() interface{} {
 
 	fmt.Println(ssPoint, wPoint, bPoint)
 
-	t, err := sim.Engine.GameBoard.TileMatrix.GetPt(bPoint)
+	t := sim.Engine.GameBoard.Get(bPoint)
 
-	if err != nil {
+	if t == nil {
 		return nil
 	}
 
@@ -24,6 +24,20 @@ func (p Point[T]) OrthogonalNeighbours() [4]Point[T] {
 	}
 }
 
+// Neighbours gets all eight surrounding points, including the diagonals
+func (p Point[T]) Neighbours() [8]Point[T] {
+	return [8]Point[T]{
+		p.North(),
+		p.North().East(),
+		p.East(),
+		p.South().East(),
+		p.South(),
+		p.South().West(),
+		p.West(),
+		p.North().West(),
+	}
+}
+
 func (p Point[T]) North() Point[T] {
 	return Point[T]{
 		X: p.X,